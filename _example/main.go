@@ -52,7 +52,9 @@ func newInstanceAwsS3Storage() (cstorage.CStorage, error) {
 func newInstanceGoogleStorage() (cstorage.CStorage, error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
-	return cstorage.NewGoogleStorage(ctx, option.WithCredentialsFile("firebase-admin-sdk.json"))
+	return cstorage.NewGoogleStorage(ctx, cstorage.WithGoogleClientOptions(
+		option.WithCredentialsFile("firebase-admin-sdk.json"),
+	))
 }
 
 func createBucket() {
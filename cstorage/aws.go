@@ -3,25 +3,63 @@ package cstorage
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
+// awsS3MinPartSize minimum size of each part of the multipart upload, except the last one
+const awsS3MinPartSize = 5 << 20
+
+// awsS3MaxParts maximum number of parts of the multipart upload
+const awsS3MaxParts = 10000
+
+// awsS3MaxCopySize maximum size of the object copied by a single request, the larger ones are copied by parts
+const awsS3MaxCopySize = 5 << 30
+
+// awsS3CopyPartSize minimum size of each part of the multipart copy
+const awsS3CopyPartSize = 512 << 20
+
+// awsS3CopyConcurrency number of parts copied at the same time
+const awsS3CopyConcurrency = 5
+
+// awsS3CopyMaxRetries maximum number of times that a failed part is copied again before aborting the copy
+const awsS3CopyMaxRetries = 3
+
 type awsS3Client struct {
-	config aws.Config
-	client *s3.Client
+	config  aws.Config
+	client  *s3.Client
+	options clientOptions
 }
 
-// NewAwsS3Storage new instance of connection with AWS S3 storage, to close it just use Disconnect() or SimpleDisconnect()
-func NewAwsS3Storage(cfg aws.Config) CStorage {
-	return &awsS3Client{
-		client: s3.NewFromConfig(cfg),
-		config: cfg,
-	}
+// NewAwsS3Storage new instance of connection with AWS S3 storage, customized by the opts param (Option), the
+// S3-compatible storages, such as MinIO, are used with the WithEndpoint and WithForcePathStyle options, to close it
+// just use Disconnect() or SimpleDisconnect()
+func NewAwsS3Storage(cfg aws.Config, opts ...Option) CStorage {
+	options := newClientOptions(opts)
+	return newClientStorage(&awsS3Client{
+		client:  s3.NewFromConfig(cfg, options.awsS3Options...),
+		config:  cfg,
+		options: options,
+	}, options)
+}
+
+func (a *awsS3Client) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(a, name)
 }
 
 func (a *awsS3Client) CreateBucket(ctx context.Context, input CreateBucketInput) error {
@@ -35,22 +73,61 @@ func (a *awsS3Client) CreateBucket(ctx context.Context, input CreateBucketInput)
 			LocationConstraint: types.BucketLocationConstraint(region),
 		},
 	})
-	return err
+	return parseAwsS3Error(err)
+}
+
+func (a *awsS3Client) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	opt := MergeOptsListBucketsByParams(opts)
+	output, err := a.client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if helper.IsNotNil(err) {
+		return nil, parseAwsS3Error(err)
+	}
+	var result []BucketSummary
+	for _, bkt := range output.Buckets {
+		// S3 doesn't filter the buckets by prefix, so we filter them before getting the location of each one
+		if !strings.HasPrefix(helper.ConvertPointerToValue(bkt.Name), opt.Prefix) {
+			continue
+		}
+		bucketSummary := parseAwsS3BucketSummary(bkt)
+		if opt.WithLocation {
+			// a bucket without permission or in a disabled region doesn't fail the listing, its location stays empty
+			location, err := a.client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+				Bucket: bkt.Name,
+			})
+			if helper.IsNil(err) {
+				bucketSummary.Location = parseAwsS3BucketLocation(location.LocationConstraint)
+			}
+		}
+		result = append(result, bucketSummary)
+	}
+	return result, nil
 }
 
-func (a *awsS3Client) PutObject(ctx context.Context, input PutObjectInput) error {
+func (a *awsS3Client) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if r, ok := contentReader(input.Content); ok {
+		return a.PutObjectStream(ctx, input, r, opts...)
+	}
 	bytesContent, err := helper.ConvertToBytes(input.Content)
 	if helper.IsNil(err) {
-		_, err = a.client.PutObject(ctx, &s3.PutObjectInput{
-			Body:        bytes.NewReader(bytesContent),
-			Bucket:      aws.String(input.Bucket),
-			ContentType: aws.String(input.MimeType.String()),
-			Key:         aws.String(input.Key),
-		})
+		err = a.PutObjectStream(ctx, input, bytes.NewReader(bytesContent), opts...)
 	}
 	return err
 }
 
+func (a *awsS3Client) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	opt := MergeOptsPutObjectByParams(opts)
+	partSize := max(opt.PartSize, awsS3MinPartSize)
+	size, ok := readerSize(r)
+	if ok && size <= partSize {
+		return parseAwsS3Error(a.putObject(ctx, input, r, size, *opt.MaxRetries))
+	} else if ok && size > partSize*awsS3MaxParts {
+		// the part size is increased so that the content fits in the maximum number of parts
+		partSize = (size + awsS3MaxParts - 1) / awsS3MaxParts
+	}
+	return parseAwsS3Error(a.putObjectMultipart(ctx, input, r, partSize, opt))
+}
+
 func (a *awsS3Client) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
 	var result []PutObjectOutput
 	for _, input := range inputs {
@@ -64,44 +141,245 @@ func (a *awsS3Client) PutObjects(ctx context.Context, inputs ...PutObjectInput)
 	return result
 }
 
+func (a *awsS3Client) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	if err := checkCopyObject(input); helper.IsNotNil(err) {
+		return err
+	}
+	head, err := a.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(input.SrcBucket),
+		Key:    aws.String(input.SrcKey),
+	})
+	if helper.IsNotNil(err) {
+		return a.parseHeadObjectError(ctx, input.SrcBucket, err)
+	}
+	if aws.ToInt64(head.ContentLength) > awsS3MaxCopySize {
+		return parseAwsS3Error(a.copyObjectMultipart(ctx, input, head))
+	}
+	_, err = a.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(input.DstBucket),
+		CopySource: aws.String(awsS3CopySource(input)),
+		Key:        aws.String(input.DstKey),
+	})
+	return parseAwsS3Error(err)
+}
+
+func (a *awsS3Client) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	err := a.CopyObject(ctx, input)
+	if helper.IsNil(err) {
+		err = a.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.SrcBucket,
+			Key:    input.SrcKey,
+		})
+	}
+	return err
+}
+
 func (a *awsS3Client) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
-	obj, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+	return readObject(a.GetObjectReader(ctx, bucket, key))
+}
+
+func (a *awsS3Client) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object, error) {
+	return a.GetObjectRangeReader(ctx, bucket, key, 0, -1)
+}
+
+func (a *awsS3Client) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	if err := checkRange(offset, length); helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}
+	if header := rangeHeader(offset, length); helper.IsNotEmpty(header) {
+		input.Range = aws.String(header)
+	}
+	obj, err := a.client.GetObject(ctx, input)
 	if helper.IsNotNil(err) {
-		return nil, err
+		return nil, nil, parseAwsS3Error(err)
 	}
-	bs, _ := io.ReadAll(obj.Body)
 	objResult := parseAwsS3StorageObject(obj)
+	objResult.Size = contentRangeSize(helper.ConvertPointerToValue(obj.ContentRange), objResult.Size)
+	objResult.Key = key
+	objResult.Url = a.GetObjectUrl(bucket, key)
+	return obj.Body, &objResult, nil
+}
+
+func (a *awsS3Client) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	obj, err := a.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if helper.IsNotNil(err) {
+		return nil, a.parseHeadObjectError(ctx, bucket, err)
+	}
+	objResult := parseAwsS3StorageHeadObject(obj)
 	objResult.Key = key
 	objResult.Url = a.GetObjectUrl(bucket, key)
-	objResult.Content = bs
 	return &objResult, nil
 }
 
+func (a *awsS3Client) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := a.StatObject(ctx, bucket, key)
+	return parseExistsError(err, ErrObjectNotFound)
+}
+
+func (a *awsS3Client) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	_, err := a.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	// the head bucket not found has no body, so its error code is the same of the head object not found
+	if isAwsS3HeadNotFound(err) {
+		return false, nil
+	}
+	return parseExistsError(parseAwsS3Error(err), ErrBucketNotFound)
+}
+
 func (a *awsS3Client) GetObjectUrl(bucket, key string) string {
-	url := "https://%s.amazonaws.com/%s/%s"
-	return fmt.Sprintf(url, a.config.Region, bucket, key)
+	if objectUrl, ok := a.options.baseObjectUrl(bucket, key); ok {
+		return objectUrl
+	}
+	return awsS3ObjectUrl(a.client.Options(), bucket, key)
+}
+
+func (a *awsS3Client) PresignGetObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	opt := MergeOptsPresignByParams(opts)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if helper.IsNotEmpty(opt.ResponseContentType) {
+		input.ResponseContentType = aws.String(opt.ResponseContentType.String())
+	}
+	if helper.IsNotEmpty(opt.ResponseContentDisposition) {
+		input.ResponseContentDisposition = aws.String(opt.ResponseContentDisposition)
+	}
+	req, err := s3.NewPresignClient(a.client).PresignGetObject(ctx, input, s3.WithPresignExpires(opt.Expires))
+	if helper.IsNotNil(err) {
+		return "", parseAwsS3Error(err)
+	}
+	return req.URL, nil
+}
+
+func (a *awsS3Client) PresignPutObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	opt := MergeOptsPresignByParams(opts)
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if helper.IsNotEmpty(opt.ContentType) {
+		input.ContentType = aws.String(opt.ContentType.String())
+	}
+	req, err := s3.NewPresignClient(a.client).PresignPutObject(ctx, input, s3.WithPresignExpires(opt.Expires))
+	if helper.IsNotNil(err) {
+		return "", parseAwsS3Error(err)
+	}
+	return req.URL, nil
+}
+
+// GeneratePostPolicy signs the policy with the signature version 4, the SDK doesn't generate the POST policies
+func (a *awsS3Client) GeneratePostPolicy(ctx context.Context, input PostPolicyInput, opts ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	opt := MergeOptsPostPolicyByParams(opts)
+	key, err := parsePostPolicyKey(input, opt)
+	if helper.IsNotNil(err) {
+		return nil, err
+	} else if helper.IsNil(a.config.Credentials) {
+		return nil, wrapError(ErrPermissionDenied, errors.New("aws config credentials are required to sign the policy"))
+	}
+	creds, err := a.config.Credentials.Retrieve(ctx)
+	if helper.IsNotNil(err) {
+		return nil, wrapError(ErrPermissionDenied, err)
+	}
+	now := time.Now().UTC()
+	date := now.Format("20060102")
+	fields := map[string]string{
+		"key":              key,
+		"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+		"x-amz-credential": strings.Join([]string{creds.AccessKeyID, date, a.config.Region, "s3", "aws4_request"}, "/"),
+		"x-amz-date":       now.Format("20060102T150405Z"),
+	}
+	if helper.IsNotEmpty(creds.SessionToken) {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+	if helper.IsNotEmpty(opt.ContentType) {
+		fields["Content-Type"] = opt.ContentType.String()
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	conditions := []any{map[string]string{"bucket": input.Bucket}}
+	for _, name := range names {
+		if name == "key" && helper.IsEmpty(input.Key) {
+			conditions = append(conditions, []string{"starts-with", "$key", input.KeyPrefix})
+		} else {
+			conditions = append(conditions, map[string]string{name: fields[name]})
+		}
+	}
+	if opt.MaxContentLength > 0 {
+		conditions = append(conditions, []any{"content-length-range", opt.MinContentLength, opt.MaxContentLength})
+	}
+	policy, err := json.Marshal(map[string]any{
+		"expiration": now.Add(opt.Expires).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	fields["policy"] = base64.StdEncoding.EncodeToString(policy)
+	// the signing key is derived from the secret by the date, region and service, and then it signs the policy
+	signingKey := []byte("AWS4" + creds.SecretAccessKey)
+	for _, value := range []string{date, a.config.Region, "s3", "aws4_request", fields["policy"]} {
+		signingKey = awsS3Hmac(signingKey, value)
+	}
+	fields["x-amz-signature"] = hex.EncodeToString(signingKey)
+	return &PostPolicy{
+		Url:    awsS3BucketUrl(a.client.Options(), input.Bucket) + "/",
+		Fields: fields,
+	}, nil
 }
 
 func (a *awsS3Client) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary, error) {
+	return listAllObjects(ctx, a, bucket, MergeOptsListObjectsByParams(opts))
+}
+
+func (a *awsS3Client) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (*ObjectPage,
+	error) {
 	opt := MergeOptsListObjectsByParams(opts)
 	input := &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Delimiter: aws.String(opt.Delimiter),
-		Prefix:    aws.String(opt.Prefix),
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(opt.Prefix),
+	}
+	if helper.IsNotEmpty(opt.Delimiter) {
+		input.Delimiter = aws.String(opt.Delimiter)
+	}
+	if opt.MaxResults > 0 {
+		input.MaxKeys = aws.Int32(int32(opt.MaxResults))
+	}
+	if helper.IsNotEmpty(opt.PageToken) {
+		input.ContinuationToken = aws.String(opt.PageToken)
 	}
 	objs, err := a.client.ListObjectsV2(ctx, input)
-	var result []ObjectSummary
-	if helper.IsNotNil(objs) {
-		for _, obj := range objs.Contents {
-			objResult := parseAwsS3StorageObjectSummary(obj)
-			objResult.Url = a.GetObjectUrl(bucket, objResult.Key)
-			result = append(result, objResult)
-		}
+	if helper.IsNotNil(err) {
+		return nil, parseAwsS3Error(err)
+	}
+	page := &ObjectPage{}
+	for _, obj := range objs.Contents {
+		objResult := parseAwsS3StorageObjectSummary(obj)
+		objResult.Url = a.GetObjectUrl(bucket, objResult.Key)
+		page.Items = append(page.Items, objResult)
+	}
+	for _, commonPrefix := range objs.CommonPrefixes {
+		page.Prefixes = append(page.Prefixes, aws.ToString(commonPrefix.Prefix))
+	}
+	if aws.ToBool(objs.IsTruncated) {
+		page.NextPageToken = aws.ToString(objs.NextContinuationToken)
 	}
-	return result, err
+	return page, nil
 }
 
 func (a *awsS3Client) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
@@ -109,7 +387,7 @@ func (a *awsS3Client) DeleteObject(ctx context.Context, input DeleteObjectInput)
 		Bucket: aws.String(input.Bucket),
 		Key:    aws.String(input.Key),
 	})
-	return err
+	return parseAwsS3Error(err)
 }
 
 func (a *awsS3Client) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
@@ -126,19 +404,30 @@ func (a *awsS3Client) DeleteObjects(ctx context.Context, inputs ...DeleteObjectI
 }
 
 func (a *awsS3Client) DeleteObjectsByPrefix(ctx context.Context, input DeletePrefixInput) error {
-	objs, err := a.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(input.Bucket),
-		Prefix: aws.String(input.Prefix),
-	})
-	if helper.IsNotNil(objs) {
-		for _, obj := range objs.Contents {
+	// each list returns at most 1000 keys, so we delete the objects page by page, the continuation token is still
+	// valid after deleting the objects of its page
+	opt := NewOptsListObjects().SetPrefix(input.Prefix)
+	var rErr error
+	for {
+		page, err := a.ListObjectsPage(ctx, input.Bucket, opt)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		for _, obj := range page.Items {
 			err = a.DeleteObject(ctx, DeleteObjectInput{
 				Bucket: input.Bucket,
-				Key:    helper.ConvertPointerToValue(obj.Key),
+				Key:    obj.Key,
 			})
+			// the other objects are still deleted, returning the first error
+			if helper.IsNil(rErr) {
+				rErr = err
+			}
+		}
+		if helper.IsEmpty(page.NextPageToken) {
+			return rErr
 		}
+		opt.PageToken = page.NextPageToken
 	}
-	return err
 }
 
 func (a *awsS3Client) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
@@ -158,7 +447,7 @@ func (a *awsS3Client) DeleteBucket(ctx context.Context, bucket string) error {
 	_, err := a.client.DeleteBucket(ctx, &s3.DeleteBucketInput{
 		Bucket: aws.String(bucket),
 	})
-	return err
+	return parseAwsS3Error(err)
 }
 
 func (a *awsS3Client) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
@@ -179,3 +468,289 @@ func (a *awsS3Client) Disconnect() error {
 
 func (a *awsS3Client) SimpleDisconnect() {
 }
+
+// parseHeadObjectError parses the error of the head object, its not found has no body, so the bucket is checked to
+// return ErrBucketNotFound when it doesn't exist, as the other providers
+func (a *awsS3Client) parseHeadObjectError(ctx context.Context, bucket string, err error) error {
+	if isAwsS3HeadNotFound(err) {
+		_, bucketErr := a.client.HeadBucket(ctx, &s3.HeadBucketInput{
+			Bucket: aws.String(bucket),
+		})
+		if isAwsS3HeadNotFound(bucketErr) {
+			return wrapError(ErrBucketNotFound, err)
+		}
+	}
+	return parseAwsS3Error(err)
+}
+
+func (a *awsS3Client) putObject(ctx context.Context, input PutObjectInput, r io.Reader, size int64,
+	maxRetries int) error {
+	// the content can only be sent again if we can go back to the beginning of it
+	seeker, ok := r.(io.Seeker)
+	var offset int64
+	var err error
+	if ok {
+		offset, err = seeker.Seek(0, io.SeekCurrent)
+	}
+	if !ok || helper.IsNotNil(err) {
+		maxRetries = 0
+	}
+	attempt := 0
+	return retry(ctx, maxRetries, func() error {
+		if attempt++; attempt > 1 {
+			if _, err := seeker.Seek(offset, io.SeekStart); helper.IsNotNil(err) {
+				return err
+			}
+		}
+		_, err := a.client.PutObject(ctx, &s3.PutObjectInput{
+			Body:               r,
+			Bucket:             aws.String(input.Bucket),
+			ContentLength:      aws.Int64(size),
+			ContentType:        aws.String(input.MimeType.String()),
+			Key:                aws.String(input.Key),
+			Metadata:           input.Metadata,
+			CacheControl:       aws.String(input.CacheControl),
+			ContentDisposition: aws.String(input.ContentDisposition),
+			ContentEncoding:    aws.String(input.ContentEncoding),
+			ContentLanguage:    aws.String(input.ContentLanguage),
+		})
+		return parseAwsS3Error(err)
+	})
+}
+
+// putObjectMultipart uploads the content by parts concurrently, holding in memory only the parts being uploaded,
+// each failed part is retried individually and the upload is aborted if any part fails
+func (a *awsS3Client) putObjectMultipart(ctx context.Context, input PutObjectInput, r io.Reader, partSize int64,
+	opt *OptsPutObject) error {
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		// content fits in a single part, so we don't need the multipart upload
+		return a.putObject(ctx, input, bytes.NewReader(buf[:n]), int64(n), *opt.MaxRetries)
+	} else if helper.IsNotNil(err) {
+		return err
+	}
+	upload, err := a.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(input.Bucket),
+		ContentType:        aws.String(input.MimeType.String()),
+		Key:                aws.String(input.Key),
+		Metadata:           input.Metadata,
+		CacheControl:       aws.String(input.CacheControl),
+		ContentDisposition: aws.String(input.ContentDisposition),
+		ContentEncoding:    aws.String(input.ContentEncoding),
+		ContentLanguage:    aws.String(input.ContentLanguage),
+	})
+	if helper.IsNotNil(err) {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var parts []types.CompletedPart
+	var rErr error
+	setErr := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if helper.IsNil(rErr) {
+			rErr = err
+			cancel()
+		}
+	}
+	// the buffers are reused by the parts, so at most Concurrency parts are in memory
+	buffers := make(chan []byte, opt.Concurrency)
+	allocated := 1
+	for partNumber := int32(1); ; partNumber++ {
+		if partNumber > awsS3MaxParts {
+			setErr(wrapError(ErrInvalidArgument,
+				errors.New("the content exceeds the maximum number of parts, increase the part size")))
+			break
+		}
+		wg.Add(1)
+		go func(partNumber int32, buf []byte, n int) {
+			defer wg.Done()
+			etag, err := a.uploadPart(ctx, input, upload.UploadId, partNumber, buf[:n], *opt.MaxRetries)
+			buffers <- buf
+			if helper.IsNotNil(err) {
+				setErr(err)
+				return
+			}
+			mutex.Lock()
+			parts = append(parts, types.CompletedPart{
+				ETag:       etag,
+				PartNumber: aws.Int32(partNumber),
+			})
+			mutex.Unlock()
+		}(partNumber, buf, n)
+		if int64(n) < partSize {
+			break
+		}
+		if allocated < opt.Concurrency {
+			allocated++
+			buf = make([]byte, partSize)
+		} else {
+			select {
+			case buf = <-buffers:
+			case <-ctx.Done():
+			}
+		}
+		if helper.IsNotNil(ctx.Err()) {
+			break
+		}
+		n, err = io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if helper.IsNotNil(err) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			setErr(err)
+			break
+		}
+	}
+	wg.Wait()
+	if helper.IsNil(rErr) {
+		rErr = ctx.Err()
+	}
+	if helper.IsNil(rErr) {
+		sort.Slice(parts, func(i, j int) bool {
+			return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+		})
+		_, rErr = a.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(input.Bucket),
+			Key:             aws.String(input.Key),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+			UploadId:        upload.UploadId,
+		})
+	}
+	if helper.IsNotNil(rErr) {
+		a.abortMultipartUpload(ctx, input, upload.UploadId)
+	}
+	return rErr
+}
+
+func (a *awsS3Client) uploadPart(ctx context.Context, input PutObjectInput, uploadId *string, partNumber int32,
+	part []byte, maxRetries int) (*string, error) {
+	var etag *string
+	err := retry(ctx, maxRetries, func() error {
+		output, err := a.client.UploadPart(ctx, &s3.UploadPartInput{
+			Body:          bytes.NewReader(part),
+			Bucket:        aws.String(input.Bucket),
+			ContentLength: aws.Int64(int64(len(part))),
+			Key:           aws.String(input.Key),
+			PartNumber:    aws.Int32(partNumber),
+			UploadId:      uploadId,
+		})
+		if helper.IsNil(err) {
+			etag = output.ETag
+		}
+		return parseAwsS3Error(err)
+	})
+	return etag, err
+}
+
+// copyObjectMultipart copies the object by parts concurrently, the parts are copied by the provider, so the content
+// is never downloaded, the source object must keep the same ETag during the copy
+func (a *awsS3Client) copyObjectMultipart(ctx context.Context, input CopyObjectInput, head *s3.HeadObjectOutput) error {
+	dst := PutObjectInput{Bucket: input.DstBucket, Key: input.DstKey}
+	upload, err := a.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(dst.Bucket),
+		ContentType:        head.ContentType,
+		Key:                aws.String(dst.Key),
+		Metadata:           head.Metadata,
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+	})
+	if helper.IsNotNil(err) {
+		return err
+	}
+	size := aws.ToInt64(head.ContentLength)
+	partSize := max(awsS3CopyPartSize, (size+awsS3MaxParts-1)/awsS3MaxParts)
+	parts := make([]types.CompletedPart, (size+partSize-1)/partSize)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var rErr error
+	setErr := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if helper.IsNil(rErr) {
+			rErr = err
+			cancel()
+		}
+	}
+	semaphore := make(chan struct{}, awsS3CopyConcurrency)
+	for i := range parts {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if helper.IsNotNil(ctx.Err()) {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			start := int64(i) * partSize
+			end := min(start+partSize, size) - 1
+			var output *s3.UploadPartCopyOutput
+			err := retry(ctx, awsS3CopyMaxRetries, func() (err error) {
+				output, err = a.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+					Bucket:            aws.String(dst.Bucket),
+					CopySource:        aws.String(awsS3CopySource(input)),
+					CopySourceIfMatch: head.ETag,
+					CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+					Key:               aws.String(dst.Key),
+					PartNumber:        aws.Int32(int32(i + 1)),
+					UploadId:          upload.UploadId,
+				})
+				return parseAwsS3Error(err)
+			})
+			if helper.IsNotNil(err) {
+				setErr(err)
+				return
+			}
+			parts[i] = types.CompletedPart{
+				ETag:       output.CopyPartResult.ETag,
+				PartNumber: aws.Int32(int32(i + 1)),
+			}
+		}(i)
+	}
+	wg.Wait()
+	if helper.IsNil(rErr) {
+		rErr = ctx.Err()
+	}
+	if helper.IsNil(rErr) {
+		_, rErr = a.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(dst.Bucket),
+			Key:             aws.String(dst.Key),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+			UploadId:        upload.UploadId,
+		})
+	}
+	if helper.IsNotNil(rErr) {
+		a.abortMultipartUpload(ctx, dst, upload.UploadId)
+	}
+	return rErr
+}
+
+// awsS3CopySource returns the source of the copy as bucket/key, with the key URL-encoded as required by S3
+func awsS3CopySource(input CopyObjectInput) string {
+	return input.SrcBucket + "/" + url.PathEscape(input.SrcKey)
+}
+
+func (a *awsS3Client) abortMultipartUpload(ctx context.Context, input PutObjectInput, uploadId *string) {
+	// the abort must happen even if the upload context was canceled
+	_, _ = a.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(input.Bucket),
+		Key:      aws.String(input.Key),
+		UploadId: uploadId,
+	})
+}
+
+func awsS3Hmac(key []byte, value string) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write([]byte(value))
+	return hash.Sum(nil)
+}
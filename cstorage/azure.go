@@ -0,0 +1,398 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+	"strings"
+	"time"
+)
+
+type azureBlobClient struct {
+	client  *azblob.Client
+	options clientOptions
+}
+
+// NewAzureBlobStorage new instance of connection with Azure Blob storage using the client already configured,
+// each bucket is a container of the storage account, customized by the opts param (Option), to close it just use
+// Disconnect() or SimpleDisconnect()
+func NewAzureBlobStorage(client *azblob.Client, opts ...Option) CStorage {
+	options := newClientOptions(opts)
+	return newClientStorage(&azureBlobClient{
+		client:  client,
+		options: options,
+	}, options)
+}
+
+// NewAzureBlobStorageFromConnectionString new instance of connection with Azure Blob storage by the storage account
+// connection string, also used to connect to the Azurite local emulator, customized by the opts param (Option), to
+// close it just use Disconnect() or SimpleDisconnect()
+func NewAzureBlobStorageFromConnectionString(connectionString string, opts ...Option) (CStorage, error) {
+	client, err := azblob.NewClientFromConnectionString(connectionString, nil)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return NewAzureBlobStorage(client, opts...), nil
+}
+
+func (a *azureBlobClient) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(a, name)
+}
+
+func (a *azureBlobClient) CreateBucket(ctx context.Context, input CreateBucketInput) error {
+	_, err := a.client.CreateContainer(ctx, input.Bucket, nil)
+	return parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	opt := MergeOptsListBucketsByParams(opts)
+	pager := a.client.NewListContainersPager(&azblob.ListContainersOptions{
+		Prefix: helper.ConvertToPointer(opt.Prefix),
+	})
+	var result []BucketSummary
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if helper.IsNotNil(err) {
+			return nil, parseAzureBlobError(err)
+		}
+		for _, item := range page.ContainerItems {
+			result = append(result, parseAzureBlobBucketSummary(item))
+		}
+	}
+	return result, nil
+}
+
+func (a *azureBlobClient) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if r, ok := contentReader(input.Content); ok {
+		return a.PutObjectStream(ctx, input, r, opts...)
+	}
+	opt := MergeOptsPutObjectByParams(opts)
+	bytesContent, err := helper.ConvertToBytes(input.Content)
+	if helper.IsNil(err) {
+		_, err = a.client.UploadBuffer(ctx, input.Bucket, input.Key, bytesContent, &azblob.UploadBufferOptions{
+			BlockSize:   opt.PartSize,
+			Concurrency: uint16(opt.Concurrency),
+			HTTPHeaders: parseAzureBlobHTTPHeaders(input),
+			Metadata:    parseAzureBlobInputMetadata(input.Metadata),
+		})
+	}
+	return parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	opt := MergeOptsPutObjectByParams(opts)
+	// the retries of each block are done by the retry policy of the client pipeline
+	_, err := a.client.UploadStream(ctx, input.Bucket, input.Key, r, &azblob.UploadStreamOptions{
+		BlockSize:   opt.PartSize,
+		Concurrency: opt.Concurrency,
+		HTTPHeaders: parseAzureBlobHTTPHeaders(input),
+		Metadata:    parseAzureBlobInputMetadata(input.Metadata),
+	})
+	return parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
+	var result []PutObjectOutput
+	for _, input := range inputs {
+		err := a.PutObject(ctx, input)
+		result = append(result, PutObjectOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (a *azureBlobClient) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	if err := checkCopyObject(input); helper.IsNotNil(err) {
+		return err
+	}
+	src := a.containerClient(input.SrcBucket).NewBlobClient(input.SrcKey)
+	dst := a.containerClient(input.DstBucket).NewBlobClient(input.DstKey)
+	// we check the source first, because azure doesn't return the not found error of the source on the copy
+	if _, err := src.GetProperties(ctx, nil); helper.IsNotNil(err) {
+		return parseAzureBlobError(err)
+	}
+	resp, err := dst.StartCopyFromURL(ctx, src.URL(), nil)
+	if helper.IsNotNil(err) {
+		return parseAzureBlobError(err)
+	}
+	// the copy is asynchronous for large blobs, so we wait for it to finish
+	status := helper.ConvertPointerToValue(resp.CopyStatus)
+	for status == blob.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		props, err := dst.GetProperties(ctx, nil)
+		if helper.IsNotNil(err) {
+			return parseAzureBlobError(err)
+		}
+		status = helper.ConvertPointerToValue(props.CopyStatus)
+	}
+	if status != blob.CopyStatusTypeSuccess {
+		return errors.New("copy of the object", input.SrcKey, "finished with status", status)
+	}
+	return nil
+}
+
+func (a *azureBlobClient) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	err := a.CopyObject(ctx, input)
+	if helper.IsNil(err) {
+		err = a.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.SrcBucket,
+			Key:    input.SrcKey,
+		})
+	}
+	return err
+}
+
+func (a *azureBlobClient) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
+	return readObject(a.GetObjectReader(ctx, bucket, key))
+}
+
+func (a *azureBlobClient) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object, error) {
+	return a.GetObjectRangeReader(ctx, bucket, key, 0, -1)
+}
+
+func (a *azureBlobClient) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	if err := checkRange(offset, length); helper.IsNotNil(err) {
+		return nil, nil, parseAzureBlobError(err)
+	}
+	httpRange := blob.HTTPRange{Offset: offset, Count: max(length, 0)}
+	if offset < 0 {
+		// azure does not support suffix ranges, so we need the size of the blob to calculate the offset
+		props, err := a.containerClient(bucket).NewBlobClient(key).GetProperties(ctx, nil)
+		if helper.IsNotNil(err) {
+			return nil, nil, parseAzureBlobError(err)
+		}
+		httpRange.Offset, _, err = rangeBounds(offset, length, helper.ConvertPointerToValue(props.ContentLength))
+		if helper.IsNotNil(err) {
+			return nil, nil, parseAzureBlobError(err)
+		}
+	}
+	obj, err := a.client.DownloadStream(ctx, bucket, key, &azblob.DownloadStreamOptions{
+		Range: httpRange,
+	})
+	if helper.IsNotNil(err) {
+		return nil, nil, parseAzureBlobError(err)
+	}
+	objResult := parseAzureBlobObject(obj)
+	objResult.Size = contentRangeSize(helper.ConvertPointerToValue(obj.ContentRange), objResult.Size)
+	objResult.Key = key
+	objResult.Url = a.GetObjectUrl(bucket, key)
+	return obj.Body, &objResult, nil
+}
+
+func (a *azureBlobClient) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	props, err := a.containerClient(bucket).NewBlobClient(key).GetProperties(ctx, nil)
+	if helper.IsNotNil(err) {
+		return nil, parseAzureBlobError(err)
+	}
+	objResult := parseAzureBlobProperties(props)
+	objResult.Key = key
+	objResult.Url = a.GetObjectUrl(bucket, key)
+	return &objResult, nil
+}
+
+func (a *azureBlobClient) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := a.StatObject(ctx, bucket, key)
+	return parseExistsError(err, ErrObjectNotFound)
+}
+
+func (a *azureBlobClient) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	_, err := a.containerClient(bucket).GetProperties(ctx, nil)
+	return parseExistsError(parseAzureBlobError(err), ErrBucketNotFound)
+}
+
+func (a *azureBlobClient) GetObjectUrl(bucket, key string) string {
+	if objectUrl, ok := a.options.baseObjectUrl(bucket, key); ok {
+		return objectUrl
+	}
+	return strings.TrimSuffix(a.client.URL(), "/") + "/" + bucket + "/" + encodeObjectKey(key)
+}
+
+// PresignGetObject returns a SAS url, the client must be created by a shared key, such as by the connection string
+func (a *azureBlobClient) PresignGetObject(_ context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	opt := MergeOptsPresignByParams(opts)
+	if helper.IsNotEmpty(opt.ResponseContentType) || helper.IsNotEmpty(opt.ResponseContentDisposition) {
+		return "", wrapError(ErrNotSupported, errors.New("azure blob storage presigned url response headers"))
+	}
+	return a.presignObject(bucket, key, sas.BlobPermissions{Read: true}, opt.Expires)
+}
+
+// PresignPutObject returns a SAS url, the client must be created by a shared key, such as by the connection string
+func (a *azureBlobClient) PresignPutObject(_ context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	opt := MergeOptsPresignByParams(opts)
+	if helper.IsNotEmpty(opt.ContentType) {
+		return "", wrapError(ErrNotSupported, errors.New("azure blob storage presigned url content type"))
+	}
+	return a.presignObject(bucket, key, sas.BlobPermissions{Create: true, Write: true}, opt.Expires)
+}
+
+func (a *azureBlobClient) GeneratePostPolicy(_ context.Context, _ PostPolicyInput, _ ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	return nil, wrapError(ErrNotSupported, errors.New("azure blob storage post policy"))
+}
+
+func (a *azureBlobClient) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary,
+	error) {
+	return listAllObjects(ctx, a, bucket, MergeOptsListObjectsByParams(opts))
+}
+
+func (a *azureBlobClient) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (*ObjectPage,
+	error) {
+	opt := MergeOptsListObjectsByParams(opts)
+	var marker *string
+	var maxResults *int32
+	if helper.IsNotEmpty(opt.PageToken) {
+		marker = helper.ConvertToPointer(opt.PageToken)
+	}
+	if opt.MaxResults > 0 {
+		maxResults = helper.ConvertToPointer(int32(opt.MaxResults))
+	}
+	var items []*container.BlobItem
+	page := &ObjectPage{}
+	if helper.IsNotEmpty(opt.Delimiter) {
+		resp, err := a.containerClient(bucket).NewListBlobsHierarchyPager(opt.Delimiter,
+			&container.ListBlobsHierarchyOptions{
+				Marker:     marker,
+				MaxResults: maxResults,
+				Prefix:     helper.ConvertToPointer(opt.Prefix),
+			}).NextPage(ctx)
+		if helper.IsNotNil(err) {
+			return nil, parseAzureBlobError(err)
+		}
+		items = resp.Segment.BlobItems
+		for _, blobPrefix := range resp.Segment.BlobPrefixes {
+			page.Prefixes = append(page.Prefixes, helper.ConvertPointerToValue(blobPrefix.Name))
+		}
+		page.NextPageToken = helper.ConvertPointerToValue(resp.NextMarker)
+	} else {
+		resp, err := a.client.NewListBlobsFlatPager(bucket, &azblob.ListBlobsFlatOptions{
+			Marker:     marker,
+			MaxResults: maxResults,
+			Prefix:     helper.ConvertToPointer(opt.Prefix),
+		}).NextPage(ctx)
+		if helper.IsNotNil(err) {
+			return nil, parseAzureBlobError(err)
+		}
+		items = resp.Segment.BlobItems
+		page.NextPageToken = helper.ConvertPointerToValue(resp.NextMarker)
+	}
+	for _, item := range items {
+		objResult := parseAzureBlobObjectSummary(item)
+		objResult.Url = a.GetObjectUrl(bucket, objResult.Key)
+		page.Items = append(page.Items, objResult)
+	}
+	return page, nil
+}
+
+func (a *azureBlobClient) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
+	_, err := a.client.DeleteBlob(ctx, input.Bucket, input.Key, nil)
+	return parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
+	var result []DeleteObjectsOutput
+	for _, input := range inputs {
+		err := a.DeleteObject(ctx, input)
+		result = append(result, DeleteObjectsOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (a *azureBlobClient) DeleteObjectsByPrefix(ctx context.Context, input DeletePrefixInput) error {
+	pager := a.client.NewListBlobsFlatPager(input.Bucket, &azblob.ListBlobsFlatOptions{
+		Prefix: helper.ConvertToPointer(input.Prefix),
+	})
+	var rErr error
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if helper.IsNotNil(err) {
+			return parseAzureBlobError(err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			err = a.DeleteObject(ctx, DeleteObjectInput{
+				Bucket: input.Bucket,
+				Key:    helper.ConvertPointerToValue(item.Name),
+			})
+			// the other objects are still deleted, returning the first error
+			if helper.IsNil(rErr) {
+				rErr = err
+			}
+		}
+	}
+	return parseAzureBlobError(rErr)
+}
+
+func (a *azureBlobClient) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
+	var result []DeletePrefixOutput
+	for _, input := range inputs {
+		err := a.DeleteObjectsByPrefix(ctx, input)
+		result = append(result, DeletePrefixOutput{
+			Bucket: input.Bucket,
+			Prefix: input.Prefix,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (a *azureBlobClient) DeleteBucket(ctx context.Context, bucket string) error {
+	// azure deletes containers with blobs, so we check it first to keep the same behavior as the other providers
+	page, err := a.client.NewListBlobsFlatPager(bucket, &azblob.ListBlobsFlatOptions{
+		MaxResults: helper.ConvertToPointer(int32(1)),
+	}).NextPage(ctx)
+	if helper.IsNotNil(err) {
+		return parseAzureBlobError(err)
+	} else if helper.IsNotNil(page.Segment) && helper.IsNotEmpty(page.Segment.BlobItems) {
+		return wrapError(ErrBucketNotEmpty, errors.New("bucket", bucket, "is not empty"))
+	}
+	_, err = a.client.DeleteContainer(ctx, bucket, nil)
+	return parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
+	var result []DeleteBucketsOutput
+	for _, bucket := range buckets {
+		err := a.DeleteBucket(ctx, bucket)
+		result = append(result, DeleteBucketsOutput{
+			Bucket: bucket,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (a *azureBlobClient) Disconnect() error {
+	return nil
+}
+
+func (a *azureBlobClient) SimpleDisconnect() {
+}
+
+func (a *azureBlobClient) presignObject(bucket, key string, permissions sas.BlobPermissions,
+	expires time.Duration) (string, error) {
+	signedUrl, err := a.containerClient(bucket).NewBlobClient(key).GetSASURL(permissions, time.Now().Add(expires), nil)
+	return signedUrl, parseAzureBlobError(err)
+}
+
+func (a *azureBlobClient) containerClient(bucket string) *container.Client {
+	return a.client.ServiceClient().NewContainerClient(bucket)
+}
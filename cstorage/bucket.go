@@ -0,0 +1,55 @@
+package cstorage
+
+import (
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"time"
+)
+
+// BucketSummary bucket returned by ListBuckets, the fields not reported by the provider are empty
+type BucketSummary struct {
+	// Name name of the bucket
+	Name string
+	// Location region where the bucket is stored, empty on Azure Blob storage, where the location is of the account,
+	// and on AWS S3 it is only filled with OptsListBuckets.WithLocation
+	Location string
+	// StorageClass default storage class of the objects, only reported by Google storage
+	StorageClass string
+	// CreatedAt creation time of the bucket, empty on Azure Blob storage, which only reports the last modification
+	CreatedAt time.Time
+}
+
+func parseAwsS3BucketSummary(bkt types.Bucket) BucketSummary {
+	return BucketSummary{
+		Name:      helper.ConvertPointerToValue(bkt.Name),
+		CreatedAt: helper.ConvertPointerToValue(bkt.CreationDate),
+	}
+}
+
+func parseAwsS3BucketLocation(location types.BucketLocationConstraint) string {
+	// buckets created in us-east-1 have no location constraint, and the old ones in eu-west-1 use EU
+	switch location {
+	case "":
+		return "us-east-1"
+	case types.BucketLocationConstraintEu:
+		return "eu-west-1"
+	}
+	return string(location)
+}
+
+func parseGoogleStorageBucketSummary(bkt *storage.BucketAttrs) BucketSummary {
+	return BucketSummary{
+		Name:         bkt.Name,
+		Location:     bkt.Location,
+		StorageClass: bkt.StorageClass,
+		CreatedAt:    bkt.Created,
+	}
+}
+
+func parseAzureBlobBucketSummary(item *service.ContainerItem) BucketSummary {
+	return BucketSummary{
+		Name: helper.ConvertPointerToValue(item.Name),
+	}
+}
@@ -0,0 +1,390 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"io"
+	"time"
+)
+
+// clientStorage applies the options of the constructors that are common to all the providers, such as the default
+// bucket, the timeout and the retries, to each call of the provider storage
+type clientStorage struct {
+	cs      CStorage
+	options clientOptions
+}
+
+// cancelReadCloser cancels the context of the reader when it is closed
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// newClientStorage returns the provider storage wrapped by the options, when none of them is informed the provider
+// storage is returned as-is
+func newClientStorage(cs CStorage, options clientOptions) CStorage {
+	if helper.IsEmpty(options.defaultBucket) && helper.IsEmpty(options.defaultMimeType) && !options.logger &&
+		options.timeout <= 0 && options.maxRetries <= 0 && options.concurrency <= 0 {
+		return cs
+	}
+	return &clientStorage{
+		cs:      cs,
+		options: options,
+	}
+}
+
+func (c *clientStorage) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(c, c.bucket(name))
+}
+
+func (c *clientStorage) CreateBucket(ctx context.Context, input CreateBucketInput) error {
+	input.Bucket = c.bucket(input.Bucket)
+	return c.do(ctx, "CreateBucket", input.Bucket, "", false, func(ctx context.Context) error {
+		return c.cs.CreateBucket(ctx, input)
+	})
+}
+
+func (c *clientStorage) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	var result []BucketSummary
+	err := c.do(ctx, "ListBuckets", "", "", true, func(ctx context.Context) (err error) {
+		result, err = c.cs.ListBuckets(ctx, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	input = c.putObjectInput(input)
+	// the uploads are retried by the providers with the MaxRetries of OptsPutObject, so the retries don't multiply
+	return c.do(ctx, "PutObject", input.Bucket, input.Key, false, func(ctx context.Context) error {
+		return c.cs.PutObject(ctx, input, c.putObjectOpts(opts)...)
+	})
+}
+
+func (c *clientStorage) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	input = c.putObjectInput(input)
+	return c.do(ctx, "PutObjectStream", input.Bucket, input.Key, false, func(ctx context.Context) error {
+		return c.cs.PutObjectStream(ctx, input, r, c.putObjectOpts(opts)...)
+	})
+}
+
+func (c *clientStorage) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
+	var result []PutObjectOutput
+	for _, input := range inputs {
+		err := c.PutObject(ctx, input)
+		result = append(result, PutObjectOutput{
+			Bucket: c.bucket(input.Bucket),
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (c *clientStorage) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	input = c.copyObjectInput(input)
+	return c.do(ctx, "CopyObject", input.SrcBucket, input.SrcKey, true, func(ctx context.Context) error {
+		return c.cs.CopyObject(ctx, input)
+	})
+}
+
+func (c *clientStorage) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	input = c.copyObjectInput(input)
+	return c.do(ctx, "MoveObject", input.SrcBucket, input.SrcKey, false, func(ctx context.Context) error {
+		return c.cs.MoveObject(ctx, input)
+	})
+}
+
+func (c *clientStorage) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
+	bucket = c.bucket(bucket)
+	var result *Object
+	err := c.do(ctx, "GetObjectByKey", bucket, key, true, func(ctx context.Context) (err error) {
+		result, err = c.cs.GetObjectByKey(ctx, bucket, key)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object, error) {
+	bucket = c.bucket(bucket)
+	return c.doReader(ctx, "GetObjectReader", bucket, key, func(ctx context.Context) (io.ReadCloser, *Object, error) {
+		return c.cs.GetObjectReader(ctx, bucket, key)
+	})
+}
+
+func (c *clientStorage) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	bucket = c.bucket(bucket)
+	return c.doReader(ctx, "GetObjectRangeReader", bucket, key, func(ctx context.Context) (io.ReadCloser, *Object,
+		error) {
+		return c.cs.GetObjectRangeReader(ctx, bucket, key, offset, length)
+	})
+}
+
+func (c *clientStorage) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	bucket = c.bucket(bucket)
+	var result *Object
+	err := c.do(ctx, "StatObject", bucket, key, true, func(ctx context.Context) (err error) {
+		result, err = c.cs.StatObject(ctx, bucket, key)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	bucket = c.bucket(bucket)
+	var result bool
+	err := c.do(ctx, "ObjectExists", bucket, key, true, func(ctx context.Context) (err error) {
+		result, err = c.cs.ObjectExists(ctx, bucket, key)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	bucket = c.bucket(bucket)
+	var result bool
+	err := c.do(ctx, "BucketExists", bucket, "", true, func(ctx context.Context) (err error) {
+		result, err = c.cs.BucketExists(ctx, bucket)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) GetObjectUrl(bucket, key string) string {
+	return c.cs.GetObjectUrl(c.bucket(bucket), key)
+}
+
+func (c *clientStorage) PresignGetObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	bucket = c.bucket(bucket)
+	var result string
+	err := c.do(ctx, "PresignGetObject", bucket, key, false, func(ctx context.Context) (err error) {
+		result, err = c.cs.PresignGetObject(ctx, bucket, key, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) PresignPutObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string,
+	error) {
+	bucket = c.bucket(bucket)
+	var result string
+	err := c.do(ctx, "PresignPutObject", bucket, key, false, func(ctx context.Context) (err error) {
+		result, err = c.cs.PresignPutObject(ctx, bucket, key, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) GeneratePostPolicy(ctx context.Context, input PostPolicyInput, opts ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	input.Bucket = c.bucket(input.Bucket)
+	var result *PostPolicy
+	err := c.do(ctx, "GeneratePostPolicy", input.Bucket, input.Key, false, func(ctx context.Context) (err error) {
+		result, err = c.cs.GeneratePostPolicy(ctx, input, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary,
+	error) {
+	bucket = c.bucket(bucket)
+	var result []ObjectSummary
+	err := c.do(ctx, "ListObjects", bucket, "", true, func(ctx context.Context) (err error) {
+		result, err = c.cs.ListObjects(ctx, bucket, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (*ObjectPage,
+	error) {
+	bucket = c.bucket(bucket)
+	var result *ObjectPage
+	err := c.do(ctx, "ListObjectsPage", bucket, "", true, func(ctx context.Context) (err error) {
+		result, err = c.cs.ListObjectsPage(ctx, bucket, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *clientStorage) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
+	input.Bucket = c.bucket(input.Bucket)
+	return c.do(ctx, "DeleteObject", input.Bucket, input.Key, true, func(ctx context.Context) error {
+		return c.cs.DeleteObject(ctx, input)
+	})
+}
+
+func (c *clientStorage) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
+	var result []DeleteObjectsOutput
+	for _, input := range inputs {
+		err := c.DeleteObject(ctx, input)
+		result = append(result, DeleteObjectsOutput{
+			Bucket: c.bucket(input.Bucket),
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (c *clientStorage) DeleteObjectsByPrefix(ctx context.Context, input DeletePrefixInput) error {
+	input.Bucket = c.bucket(input.Bucket)
+	return c.do(ctx, "DeleteObjectsByPrefix", input.Bucket, input.Prefix, true, func(ctx context.Context) error {
+		return c.cs.DeleteObjectsByPrefix(ctx, input)
+	})
+}
+
+func (c *clientStorage) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
+	var result []DeletePrefixOutput
+	for _, input := range inputs {
+		err := c.DeleteObjectsByPrefix(ctx, input)
+		result = append(result, DeletePrefixOutput{
+			Bucket: c.bucket(input.Bucket),
+			Prefix: input.Prefix,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (c *clientStorage) DeleteBucket(ctx context.Context, bucket string) error {
+	bucket = c.bucket(bucket)
+	return c.do(ctx, "DeleteBucket", bucket, "", false, func(ctx context.Context) error {
+		return c.cs.DeleteBucket(ctx, bucket)
+	})
+}
+
+func (c *clientStorage) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
+	var result []DeleteBucketsOutput
+	for _, bucket := range buckets {
+		err := c.DeleteBucket(ctx, bucket)
+		result = append(result, DeleteBucketsOutput{
+			Bucket: c.bucket(bucket),
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (c *clientStorage) Disconnect() error {
+	return c.cs.Disconnect()
+}
+
+func (c *clientStorage) SimpleDisconnect() {
+	c.cs.SimpleDisconnect()
+}
+
+// bucket returns the bucket passed or the default bucket when it is empty
+func (c *clientStorage) bucket(bucket string) string {
+	if helper.IsEmpty(bucket) {
+		return c.options.defaultBucket
+	}
+	return bucket
+}
+
+func (c *clientStorage) putObjectInput(input PutObjectInput) PutObjectInput {
+	input.Bucket = c.bucket(input.Bucket)
+	if helper.IsEmpty(input.MimeType) {
+		input.MimeType = c.options.defaultMimeType
+	}
+	return input
+}
+
+// putObjectOpts returns the opts param preceded by the default concurrency, so the opts param has priority
+func (c *clientStorage) putObjectOpts(opts []*OptsPutObject) []*OptsPutObject {
+	if c.options.concurrency <= 0 {
+		return opts
+	}
+	return append([]*OptsPutObject{NewOptsPutObject().SetConcurrency(c.options.concurrency)}, opts...)
+}
+
+func (c *clientStorage) copyObjectInput(input CopyObjectInput) CopyObjectInput {
+	input.SrcBucket = c.bucket(input.SrcBucket)
+	input.DstBucket = c.bucket(input.DstBucket)
+	return input
+}
+
+// context returns the context of each attempt, with the timeout when it is informed
+func (c *clientStorage) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.options.timeout > 0 {
+		return context.WithTimeout(ctx, c.options.timeout)
+	}
+	return ctx, func() {}
+}
+
+// do runs fn with the timeout, and when retry is true, it runs fn again while it fails with an unknown error, the
+// cstorage errors, such as ErrObjectNotFound, are not retried because they don't change on the next attempts
+func (c *clientStorage) do(ctx context.Context, operation, bucket, key string, retry bool,
+	fn func(ctx context.Context) error) error {
+	attempt := func() error {
+		attemptCtx, cancel := c.context(ctx)
+		defer cancel()
+		return fn(attemptCtx)
+	}
+	var err error
+	if retry {
+		err = c.retry(ctx, attempt)
+	} else {
+		err = attempt()
+	}
+	c.logError(operation, bucket, key, err)
+	return err
+}
+
+// doReader runs fn like do, but the timeout is canceled only when the reader is closed, because it is read after
+// the return
+func (c *clientStorage) doReader(ctx context.Context, operation, bucket, key string,
+	fn func(ctx context.Context) (io.ReadCloser, *Object, error)) (io.ReadCloser, *Object, error) {
+	var reader io.ReadCloser
+	var obj *Object
+	err := c.retry(ctx, func() (err error) {
+		attemptCtx, cancel := c.context(ctx)
+		reader, obj, err = fn(attemptCtx)
+		if helper.IsNotNil(err) {
+			cancel()
+			return err
+		}
+		reader = cancelReadCloser{ReadCloser: reader, cancel: cancel}
+		return nil
+	})
+	c.logError(operation, bucket, key, err)
+	return reader, obj, err
+}
+
+func (c *clientStorage) retry(ctx context.Context, fn func() error) error {
+	backoff := c.options.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if helper.IsNil(err) || attempt >= c.options.maxRetries || isCStorageError(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *clientStorage) logError(operation, bucket, key string, err error) {
+	if !c.options.logger || helper.IsNil(err) {
+		return
+	}
+	v := []any{"cstorage:", operation, "bucket:", bucket, "key:", key, "err:", err}
+	if helper.IsNotNil(c.options.loggerOptions) {
+		logger.ErrorOpts(*c.options.loggerOptions, v...)
+	} else {
+		logger.Error(v...)
+	}
+}
+
+func (c cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
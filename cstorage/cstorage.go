@@ -2,6 +2,7 @@ package cstorage
 
 import (
 	"context"
+	"io"
 )
 
 // CreateBucketInput input for creating a bucket
@@ -22,8 +23,46 @@ type PutObjectInput struct {
 	Key string
 	// MimeType type of content of the object that will be created (required)
 	MimeType MimeType
-	// Content of the object that will be created (required)
+	// Content of the object that will be created, when it is an io.Reader it is streamed as-is without
+	// buffering the whole content (required, ignored by PutObjectStream)
 	Content any
+	// Metadata custom metadata of the object, AWS S3 and Azure Blob storage return the keys in lowercase, so
+	// prefer lowercase keys
+	Metadata map[string]string
+	// CacheControl value of the Cache-Control header returned when the object is downloaded
+	CacheControl string
+	// ContentDisposition value of the Content-Disposition header returned when the object is downloaded,
+	// such as attachment; filename="report.pdf"
+	ContentDisposition string
+	// ContentEncoding value of the Content-Encoding header of the content, such as gzip
+	ContentEncoding string
+	// ContentLanguage value of the Content-Language header of the content, such as en-US
+	ContentLanguage string
+}
+
+// CopyObjectInput input to copy an object, the content, mime type, metadata and headers of the source object are
+// copied to the destination object
+type CopyObjectInput struct {
+	// SrcBucket name of the bucket of the source object (required)
+	SrcBucket string
+	// SrcKey key of the source object (required)
+	SrcKey string
+	// DstBucket name of the bucket where the object will be copied, it can be the same as the SrcBucket (required)
+	DstBucket string
+	// DstKey key of the destination object, when it exists it is overwritten (required)
+	DstKey string
+}
+
+// PostPolicyInput input to generate the policy of an HTML form upload, the Key or the KeyPrefix is required
+type PostPolicyInput struct {
+	// Bucket name of the bucket where the object will be uploaded (required)
+	Bucket string
+	// Key of the object that will be uploaded, if empty the form can upload any key starting with the KeyPrefix
+	Key string
+	// KeyPrefix prefix of the key of the object, used when the Key is empty, the key field of the form is the
+	// KeyPrefix followed by ${filename}, replaced by the name of the uploaded file, and it can be changed by the
+	// client keeping the prefix
+	KeyPrefix string
 }
 
 // DeletePrefixInput input to remove a folder (prefix) of objects from the bucket
@@ -44,6 +83,15 @@ type PutObjectOutput struct {
 	Err error
 }
 
+// PostPolicy form of an HTML form upload, the form must be sent by a POST request with the multipart/form-data
+// encoding to the Url, with all the Fields and the file field last, containing the content of the object
+type PostPolicy struct {
+	// Url where the form is sent
+	Url string
+	// Fields of the form, including the signed policy
+	Fields map[string]string
+}
+
 // DeleteObjectInput input for removing an object from the bucket
 type DeleteObjectInput struct {
 	// Bucket name of the bucket where the object will be deleted (required)
@@ -81,18 +129,64 @@ type DeleteBucketsOutput struct {
 }
 
 type CStorage interface {
+	// Bucket returns the handle of the bucket, which calls the methods of the CStorage without the bucket param, the
+	// bucket is not created or checked
+	Bucket(name string) *BucketHandle
 	// CreateBucket creates the Bucket in the project.
 	CreateBucket(ctx context.Context, input CreateBucketInput) error
-	// PutObject set the value passed in the indicated bucket
-	PutObject(ctx context.Context, input PutObjectInput) error
+	// ListBuckets lists the buckets sorted by name, on Google storage the project is informed by
+	// OptsListBuckets.ProjectId
+	ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error)
+	// PutObject set the value passed in the indicated bucket, large contents are uploaded by parts as configured
+	// by OptsPutObject
+	PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error
+	// PutObjectStream set the content read from r in the indicated bucket, streaming it without buffering the whole
+	// content in memory, the input Content field is ignored, large contents are uploaded by parts as configured by
+	// OptsPutObject
+	PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader, opts ...*OptsPutObject) error
 	// PutObjects set multiple values passed in the indicated bucket
 	PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput
+	// CopyObject copies the object inside the provider, without downloading the content, the buckets can be
+	// different
+	CopyObject(ctx context.Context, input CopyObjectInput) error
+	// MoveObject moves the object by copying it and removing the source object, the source object is only removed
+	// when the copy succeeds
+	MoveObject(ctx context.Context, input CopyObjectInput) error
 	// GetObjectByKey returns the data for the object by name
 	GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error)
+	// GetObjectReader returns a reader of the object content, streaming it without buffering the whole content in
+	// memory, and the object data without the Content field, the reader must be closed by the caller
+	GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object, error)
+	// GetObjectRangeReader returns a reader of length bytes of the object content starting at offset, if length is
+	// negative the content is read until the end, and if offset is negative the last -offset bytes are read (length
+	// must be negative), the object Size field is the size of the whole object, the reader must be closed by the caller
+	GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, *Object, error)
+	// StatObject returns the data for the object by name without the Content field, without downloading it
+	StatObject(ctx context.Context, bucket, key string) (*Object, error)
+	// ObjectExists reports if the object exists, when the object is not found returns false without error, when the
+	// bucket is not found returns ErrBucketNotFound
+	ObjectExists(ctx context.Context, bucket, key string) (bool, error)
+	// BucketExists reports if the bucket exists, when it is not found returns false without error
+	BucketExists(ctx context.Context, bucket string) (bool, error)
 	// GetObjectUrl returns the object public url
 	GetObjectUrl(bucket, key string) string
-	// ListObjects return list objects by bucket, custom query using opts param (OptsListObjects)
+	// PresignGetObject returns a signed url to download the object without credentials until it expires, custom
+	// using opts param (OptsPresign), Local and Memory storage return ErrNotSupported
+	PresignGetObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string, error)
+	// PresignPutObject returns a signed url to upload the object with a PUT request without credentials until it
+	// expires, custom using opts param (OptsPresign), on Azure Blob storage the request must send the
+	// x-ms-blob-type: BlockBlob header, Local and Memory storage return ErrNotSupported
+	PresignPutObject(ctx context.Context, bucket, key string, opts ...*OptsPresign) (string, error)
+	// GeneratePostPolicy returns the url and the fields of an HTML form to upload the object without credentials
+	// until it expires, the upload is limited by the conditions of the opts param (OptsPostPolicy), Azure Blob,
+	// Local and Memory storage return ErrNotSupported
+	GeneratePostPolicy(ctx context.Context, input PostPolicyInput, opts ...*OptsPostPolicy) (*PostPolicy, error)
+	// ListObjects return list objects by bucket, custom query using opts param (OptsListObjects), when the
+	// Delimiter is informed the common prefixes are also returned sorted with the objects, with IsPrefix true
 	ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary, error)
+	// ListObjectsPage return a single page of objects by bucket, to get the next page pass the ObjectPage
+	// NextPageToken in the OptsListObjects PageToken, custom query using opts param (OptsListObjects)
+	ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (*ObjectPage, error)
 	// DeleteObject deletes the single specified object
 	DeleteObject(ctx context.Context, input DeleteObjectInput) error
 	// DeleteObjects deletes multiple objects specified in the input
@@ -1,9 +1,12 @@
 package cstorage
 
 import (
+	"bytes"
 	"context"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	"io"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -23,6 +26,22 @@ func TestCStorageCreateBucket(t *testing.T) {
 	}
 }
 
+func TestCStorageListBuckets(t *testing.T) {
+	for _, tt := range initListTestListBuckets() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.ListBuckets(ctx, tt.opts)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("ListBuckets() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("ListBuckets() result = %v, err = %v", result, err)
+		})
+	}
+}
+
 func TestCStoragePutObject(t *testing.T) {
 	for _, tt := range initListTestPutObject() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -37,6 +56,21 @@ func TestCStoragePutObject(t *testing.T) {
 	}
 }
 
+func TestCStoragePutObjectStream(t *testing.T) {
+	for _, tt := range initListTestPutObject() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			r := bytes.NewReader(helper.SimpleConvertToBytes(tt.input.Content))
+			err := tt.cstorage.PutObjectStream(ctx, tt.input, r, initTestOptsPutObject())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("PutObjectStream() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+			}
+		})
+	}
+}
+
 func TestCStoragePutObjects(t *testing.T) {
 	for _, tt := range initListTestPutObject() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -48,6 +82,126 @@ func TestCStoragePutObjects(t *testing.T) {
 	}
 }
 
+func TestCStorageCopyObject(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			err := tt.cstorage.CopyObject(ctx, initTestCopyObjectInput(tt.key, "copy"))
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("CopyObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestCStorageMoveObject(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			err := tt.cstorage.MoveObject(ctx, initTestCopyObjectInput(tt.key, "moved"))
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("MoveObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	for _, tt := range initListTestTransfer() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.src)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			err := Transfer(ctx, tt.src, tt.dst, CopyObjectInput{
+				SrcBucket: tt.bucket,
+				SrcKey:    tt.key,
+				DstBucket: tt.bucket,
+				DstKey:    tt.key,
+			}, initTestOptsPutObject())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("Transfer() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	for _, tt := range initListTestTransfer() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.src)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := Mirror(ctx, tt.src, tt.dst, MirrorInput{
+				SrcBucket: tt.bucket,
+				DstBucket: tt.bucket,
+				Prefix:    tt.key,
+			}, initTestOptsMirror())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("Mirror() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			for _, output := range result {
+				if helper.IsNotNil(output.Err) {
+					logger.Errorf("Mirror() key = %v, err = %v", output.Key, output.Err)
+					t.Fail()
+				}
+			}
+			logger.Infof("Mirror() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestSyncUp(t *testing.T) {
+	for _, tt := range initListTestSync() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := SyncUp(ctx, tt.cstorage, initTestSyncDir(), tt.bucket, "sync", initTestOptsSync())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("SyncUp() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("SyncUp() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestSyncDown(t *testing.T) {
+	for _, tt := range initListTestSync() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := SyncDown(ctx, tt.cstorage, initTestSyncDir(), tt.bucket, "", initTestOptsSync())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("SyncDown() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("SyncDown() result = %v, err = %v", result, err)
+		})
+	}
+}
+
 func TestCStorageGetObjectByKey(t *testing.T) {
 	for _, tt := range initListTestGetObjectByKey() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -72,6 +226,111 @@ func TestCStorageGetObjectByKey(t *testing.T) {
 	}
 }
 
+func TestCStorageGetObjectReader(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			reader, result, err := tt.cstorage.GetObjectReader(ctx, bucketNameDefault, tt.key)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("GetObjectReader() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			} else if helper.IsNotNil(reader) {
+				defer reader.Close()
+				bs, _ := io.ReadAll(reader)
+				logger.Infof("GetObjectReader() result = %v, content = %s, err = %v", result, bs, err)
+			} else {
+				logger.Infof("GetObjectReader() result = %v, err = %v", result, err)
+			}
+		})
+	}
+}
+
+func TestCStorageGetObjectRangeReader(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			reader, result, err := tt.cstorage.GetObjectRangeReader(ctx, bucketNameDefault, tt.key, 0, 10)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("GetObjectRangeReader() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			} else if helper.IsNotNil(reader) {
+				defer reader.Close()
+				bs, _ := io.ReadAll(reader)
+				logger.Infof("GetObjectRangeReader() result = %v, content = %s, err = %v", result, bs, err)
+			} else {
+				logger.Infof("GetObjectRangeReader() result = %v, err = %v", result, err)
+			}
+		})
+	}
+}
+
+func TestCStorageStatObject(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.StatObject(ctx, bucketNameDefault, tt.key)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("StatObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("StatObject() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStorageObjectExists(t *testing.T) {
+	for _, tt := range initListTestGetObjectByKey() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.ObjectExists(ctx, bucketNameDefault, tt.key)
+			if (err != nil || !result) != tt.wantErr {
+				logger.Errorf("ObjectExists() result = %v, err = %v, wantErr = %v", result, err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("ObjectExists() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStorageBucketExists(t *testing.T) {
+	for _, tt := range initListTestDeleteBucket() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initBucket(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.BucketExists(ctx, tt.bucket)
+			if (err != nil || !result) != tt.wantErr {
+				logger.Errorf("BucketExists() result = %v, err = %v, wantErr = %v", result, err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("BucketExists() result = %v, err = %v", result, err)
+		})
+	}
+}
+
 func TestCStorageGetObjectUrl(t *testing.T) {
 	for _, tt := range initListTestGetObjectByKey() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -81,6 +340,143 @@ func TestCStorageGetObjectUrl(t *testing.T) {
 	}
 }
 
+func TestCStoragePresignGetObject(t *testing.T) {
+	for _, tt := range initListTestPresignObject() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.PresignGetObject(ctx, bucketNameDefault, objectKeyDefault, tt.opts)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("PresignGetObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("PresignGetObject() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStoragePresignPutObject(t *testing.T) {
+	for _, tt := range initListTestPresignObject() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.PresignPutObject(ctx, bucketNameDefault, objectKeyDefault, tt.opts)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("PresignPutObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("PresignPutObject() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStorageGeneratePostPolicy(t *testing.T) {
+	for _, tt := range initListTestGeneratePostPolicy() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.GeneratePostPolicy(ctx, tt.input, initTestOptsPostPolicy())
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("GeneratePostPolicy() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("GeneratePostPolicy() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	Register("custom", func(ctx context.Context, u *url.URL, opts ...Option) (CStorage, error) {
+		return NewMemoryStorage(opts...), nil
+	})
+	for _, tt := range initListTestOpen() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := Open(ctx, tt.url)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("Open() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			} else if helper.IsNotNil(result) {
+				logger.Infof("Open() url = %v", result.GetObjectUrl("", objectKeyDefault))
+			}
+		})
+	}
+}
+
+func TestCStorageWithOptions(t *testing.T) {
+	for _, tt := range initListTestClientOptions() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			err := tt.cstorage.PutObject(ctx, PutObjectInput{
+				Bucket:  tt.bucket,
+				Key:     objectKeyDefault,
+				Content: "test with options",
+			})
+			var result *Object
+			if helper.IsNil(err) {
+				result, err = tt.cstorage.GetObjectByKey(ctx, tt.bucket, objectKeyDefault)
+			}
+			if (err != nil || result.MimeType != MimeTypeText) != tt.wantErr {
+				logger.Errorf("CStorage with options err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("CStorage with options result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStorageWithRetry(t *testing.T) {
+	for _, tt := range initListTestClientRetry() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			flaky := &flakyStorage{CStorage: initMemoryStorageWithOptions(), failures: tt.failures}
+			input := initTestPutObjectInput()
+			_ = flaky.PutObject(ctx, input)
+			cs := newClientStorage(flaky, newClientOptions([]Option{WithRetry(tt.maxRetries, time.Millisecond)}))
+			result, err := cs.StatObject(ctx, input.Bucket, input.Key)
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("StatObject() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("StatObject() result = %v, err = %v", result, err)
+		})
+	}
+}
+
+func TestCStorageWithRetryPutObject(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	flaky := &flakyStorage{CStorage: initMemoryStorageWithOptions(), putFailures: 2}
+	cs := newClientStorage(flaky, newClientOptions([]Option{WithRetry(2, time.Millisecond)}))
+	// the uploads are only retried by the providers, so the wrapper must not call it again
+	err := cs.PutObject(ctx, initTestPutObjectInput())
+	if helper.IsNil(err) || flaky.putFailures != 1 {
+		logger.Errorf("PutObject() err = %v, putFailures = %v, want = 1", err, flaky.putFailures)
+		t.Fail()
+	}
+}
+
+func TestCStorageGetObjectUrlEncoded(t *testing.T) {
+	for _, tt := range initListTestObjectUrl() {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.cstorage.GetObjectUrl(tt.bucket, tt.key)
+			if output != tt.want {
+				logger.Errorf("GetObjectUrl() output = %v, want = %v", output, tt.want)
+				t.Fail()
+			}
+		})
+	}
+}
+
 func TestCStorageListObjects(t *testing.T) {
 	for _, tt := range initListTestListObjects() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -100,6 +496,25 @@ func TestCStorageListObjects(t *testing.T) {
 	}
 }
 
+func TestCStorageListObjectsPage(t *testing.T) {
+	for _, tt := range initListTestListObjects() {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				initObject(tt.cstorage)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			result, err := tt.cstorage.ListObjectsPage(ctx, tt.bucket, tt.opts, NewOptsListObjects().SetMaxResults(10))
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("ListObjectsPage() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			}
+			logger.Infof("ListObjectsPage() result = %v, err = %v", result, err)
+		})
+	}
+}
+
 func TestCStorageDeleteObject(t *testing.T) {
 	for _, tt := range initListTestDeleteObject() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -195,6 +610,43 @@ func TestCStorageDeleteBuckets(t *testing.T) {
 	}
 }
 
+func TestCStorageBucket(t *testing.T) {
+	for _, tt := range initListTestBucketHandle() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			h := tt.cstorage.Bucket(tt.bucket)
+			err := h.Put(ctx, PutObjectInput{Key: objectKeyDefault, MimeType: MimeTypeJson, Content: initTestStruct()})
+			if (err != nil) != tt.wantErr {
+				logger.Errorf("Put() err = %v, wantErr = %v", err, tt.wantErr)
+				t.Fail()
+				return
+			} else if tt.wantErr {
+				return
+			}
+			obj, err := h.Stat(ctx, objectKeyDefault)
+			if helper.IsNotNil(err) || h.Url(objectKeyDefault) != obj.Url {
+				logger.Errorf("Stat() obj = %v, err = %v, Url() = %v", obj, err, h.Url(objectKeyDefault))
+				t.Fail()
+				return
+			}
+			objs, err := h.List(ctx)
+			if helper.IsNotNil(err) || helper.IsEmpty(objs) {
+				logger.Errorf("List() result = %v, err = %v", objs, err)
+				t.Fail()
+				return
+			}
+			err = h.Delete(ctx, objectKeyDefault)
+			if helper.IsNotNil(err) {
+				logger.Errorf("Delete() err = %v", err)
+				t.Fail()
+				return
+			}
+			logger.Info("result objs:", objs)
+		})
+	}
+}
+
 func TestCStorageDisconnect(t *testing.T) {
 	for _, tt := range initListTestDisconnect() {
 		t.Run(tt.name, func(t *testing.T) {
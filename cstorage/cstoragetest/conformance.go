@@ -0,0 +1,1171 @@
+// Package cstoragetest provides a conformance suite to guarantee that every cstorage.CStorage implementation,
+// including the ones written outside this module, behaves identically.
+package cstoragetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-cloud-storage/cstorage"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Options conformance suite options
+type Options struct {
+	// ProjectId project id where the buckets will be created (required only google storage)
+	ProjectId string
+	// Location of the buckets, if empty using default region
+	Location string
+	// BucketPrefix prefix of the name of the buckets created by the suite, if empty using "cstoragetest"
+	BucketPrefix string
+	// Timeout of each operation, if empty using 30 seconds
+	Timeout time.Duration
+}
+
+type suite struct {
+	factory func() cstorage.CStorage
+	opts    Options
+}
+
+type testContent struct {
+	Name    string  `json:"name"`
+	Balance float64 `json:"balance"`
+}
+
+var bucketSequence atomic.Int64
+
+// RunConformance runs the conformance suite with the default options, each test creates a new instance using
+// the factory and its own bucket, which is removed at the end of the test.
+func RunConformance(t *testing.T, factory func() cstorage.CStorage) {
+	RunConformanceWithOptions(t, factory, Options{})
+}
+
+// RunConformanceWithOptions runs the conformance suite with custom options, each test creates a new instance
+// using the factory and its own bucket, which is removed at the end of the test.
+func RunConformanceWithOptions(t *testing.T, factory func() cstorage.CStorage, opts Options) {
+	if helper.IsEmpty(opts.BucketPrefix) {
+		opts.BucketPrefix = "cstoragetest"
+	}
+	if helper.IsEmpty(opts.Timeout) {
+		opts.Timeout = 30 * time.Second
+	}
+	s := suite{factory: factory, opts: opts}
+	t.Run("CreateBucket", s.testCreateBucket)
+	t.Run("ListBuckets", s.testListBuckets)
+	t.Run("PutObject", s.testPutObject)
+	t.Run("PutObjectOverwrite", s.testPutObjectOverwrite)
+	t.Run("PutObjectMetadata", s.testPutObjectMetadata)
+	t.Run("PutObjectReader", s.testPutObjectReader)
+	t.Run("PutObjectStream", s.testPutObjectStream)
+	t.Run("PutObjectStreamByParts", s.testPutObjectStreamByParts)
+	t.Run("PutObjects", s.testPutObjects)
+	t.Run("PutObjectBucketNotFound", s.testPutObjectBucketNotFound)
+	t.Run("CopyObject", s.testCopyObject)
+	t.Run("CopyObjectOtherBucket", s.testCopyObjectOtherBucket)
+	t.Run("CopyObjectInvalid", s.testCopyObjectInvalid)
+	t.Run("MoveObject", s.testMoveObject)
+	t.Run("Transfer", s.testTransfer)
+	t.Run("Mirror", s.testMirror)
+	t.Run("SyncUp", s.testSyncUp)
+	t.Run("SyncDown", s.testSyncDown)
+	t.Run("GetObjectByKeyNotFound", s.testGetObjectByKeyNotFound)
+	t.Run("GetObjectReader", s.testGetObjectReader)
+	t.Run("GetObjectReaderNotFound", s.testGetObjectReaderNotFound)
+	t.Run("GetObjectRangeReader", s.testGetObjectRangeReader)
+	t.Run("GetObjectRangeReaderInvalid", s.testGetObjectRangeReaderInvalid)
+	t.Run("StatObject", s.testStatObject)
+	t.Run("ObjectExists", s.testObjectExists)
+	t.Run("BucketExists", s.testBucketExists)
+	t.Run("GetObjectUrl", s.testGetObjectUrl)
+	t.Run("PresignObject", s.testPresignObject)
+	t.Run("GeneratePostPolicy", s.testGeneratePostPolicy)
+	t.Run("ListObjects", s.testListObjects)
+	t.Run("ListObjectsPrefix", s.testListObjectsPrefix)
+	t.Run("ListObjectsDelimiter", s.testListObjectsDelimiter)
+	t.Run("ListObjectsBucketNotFound", s.testListObjectsBucketNotFound)
+	t.Run("ListObjectsPage", s.testListObjectsPage)
+	t.Run("ListObjectsPageDelimiter", s.testListObjectsPageDelimiter)
+	t.Run("DeleteObject", s.testDeleteObject)
+	t.Run("DeleteObjects", s.testDeleteObjects)
+	t.Run("DeleteObjectsByPrefix", s.testDeleteObjectsByPrefix)
+	t.Run("DeleteObjectsByPrefixes", s.testDeleteObjectsByPrefixes)
+	t.Run("DeleteBucket", s.testDeleteBucket)
+	t.Run("DeleteBucketNotEmpty", s.testDeleteBucketNotEmpty)
+	t.Run("DeleteBuckets", s.testDeleteBuckets)
+	t.Run("BucketHandle", s.testBucketHandle)
+	t.Run("Disconnect", s.testDisconnect)
+}
+
+func (s suite) testCreateBucket(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	err := cs.CreateBucket(ctx, s.createBucketInput(bucket))
+	assertErrorIs(t, "CreateBucket() with existing bucket", err, cstorage.ErrBucketAlreadyExists)
+	err = cs.CreateBucket(ctx, cstorage.CreateBucketInput{})
+	assertError(t, "CreateBucket() without name", err)
+}
+
+func (s suite) testListBuckets(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	opts := cstorage.NewOptsListBuckets().SetProjectId(s.opts.ProjectId).SetWithLocation(true)
+	bkts, err := cs.ListBuckets(ctx, opts.SetPrefix(bucket))
+	assertNoError(t, "ListBuckets()", err)
+	found := false
+	for _, bkt := range bkts {
+		assertTrue(t, "ListBuckets() name has the prefix", strings.HasPrefix(bkt.Name, bucket))
+		found = found || bkt.Name == bucket
+	}
+	assertTrue(t, "ListBuckets() contains the bucket", found)
+	bkts, err = cs.ListBuckets(ctx, opts.SetPrefix(bucket+"-not-exists"))
+	assertNoError(t, "ListBuckets() with prefix not found", err)
+	assertTrue(t, "ListBuckets() with prefix not found is empty", helper.IsEmpty(bkts))
+}
+
+func (s suite) testPutObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	content := testContent{Name: "Foo Bar", Balance: 203.12}
+	s.putObject(t, cs, bucket, "object.json", cstorage.MimeTypeJson, content)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "object.json")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() key", obj.Key, "object.json")
+	assertEqual(t, "GetObjectByKey() mime type", obj.MimeType, cstorage.MimeTypeJson)
+	assertEqual(t, "GetObjectByKey() size", obj.Size, int64(len(obj.Content)))
+	assertEqual(t, "GetObjectByKey() url", obj.Url, cs.GetObjectUrl(bucket, "object.json"))
+	assertTrue(t, "GetObjectByKey() last modified is not empty", !obj.LastModifiedAt.IsZero())
+	var dest testContent
+	err = obj.ParseContent(&dest)
+	assertNoError(t, "ParseContent()", err)
+	assertEqual(t, "ParseContent() content", dest, content)
+}
+
+func (s suite) testPutObjectOverwrite(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeText, "first content")
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeJson, "second")
+	obj, err := cs.GetObjectByKey(ctx, bucket, "object.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() content", string(obj.Content), "second")
+	assertEqual(t, "GetObjectByKey() mime type", obj.MimeType, cstorage.MimeTypeJson)
+	assertEqual(t, "GetObjectByKey() size", obj.Size, int64(len("second")))
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects()", err)
+	assertEqual(t, "ListObjects() keys", summaryKeys(objs), []string{"object.txt"})
+}
+
+func (s suite) testPutObjectMetadata(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	input := s.putObjectMetadata(t, cs, bucket, "object.txt")
+	obj, err := cs.GetObjectByKey(ctx, bucket, "object.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertObjectMetadata(t, "GetObjectByKey()", obj, input)
+	assertEqual(t, "GetObjectByKey() content", string(obj.Content), "metadata content")
+	obj, err = cs.StatObject(ctx, bucket, "object.txt")
+	assertNoError(t, "StatObject()", err)
+	assertObjectMetadata(t, "StatObject()", obj, input)
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeText, "overwritten")
+	obj, err = cs.StatObject(ctx, bucket, "object.txt")
+	assertNoError(t, "StatObject() after overwrite", err)
+	assertTrue(t, "StatObject() metadata is empty after overwrite", helper.IsEmpty(obj.Metadata))
+	assertEqual(t, "StatObject() cache control after overwrite", obj.CacheControl, "")
+}
+
+func (s suite) testPutObjectReader(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	err := cs.PutObject(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      "object.txt",
+		MimeType: cstorage.MimeTypeText,
+		Content:  strings.NewReader("content from reader"),
+	})
+	assertNoError(t, "PutObject() with reader content", err)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "object.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() content", string(obj.Content), "content from reader")
+}
+
+func (s suite) testPutObjectStream(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	content := strings.Repeat("stream content ", 1024)
+	// the multi reader hides the size of the content, as it happens with network streams
+	r := io.MultiReader(strings.NewReader(content[:100]), strings.NewReader(content[100:]))
+	err := cs.PutObjectStream(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      "dir/stream.txt",
+		MimeType: cstorage.MimeTypeText,
+		Content:  "ignored content",
+	}, r)
+	assertNoError(t, "PutObjectStream()", err)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "dir/stream.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() content", string(obj.Content), content)
+	assertEqual(t, "GetObjectByKey() mime type", obj.MimeType, cstorage.MimeTypeText)
+	assertEqual(t, "GetObjectByKey() size", obj.Size, int64(len(content)))
+	err = cs.PutObjectStream(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket + "-not-exists",
+		Key:      "stream.txt",
+		MimeType: cstorage.MimeTypeText,
+	}, strings.NewReader(content))
+	assertErrorIs(t, "PutObjectStream() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testPutObjectStreamByParts(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	// content greater than two parts, with the last part smaller than the others
+	content := []byte(strings.Repeat("0123456789abcdef", 12<<16))
+	opts := cstorage.NewOptsPutObject().SetPartSize(5 << 20).SetConcurrency(2).SetMaxRetries(1)
+	err := cs.PutObjectStream(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      "large.txt",
+		MimeType: cstorage.MimeTypeText,
+	}, io.MultiReader(bytes.NewReader(content)), opts)
+	assertNoError(t, "PutObjectStream() by parts", err)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "large.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	if helper.IsNotNil(err) {
+		return
+	}
+	assertEqual(t, "GetObjectByKey() size", obj.Size, int64(len(content)))
+	assertTrue(t, "GetObjectByKey() content", bytes.Equal(obj.Content, content))
+	assertEqual(t, "GetObjectByKey() mime type", obj.MimeType, cstorage.MimeTypeText)
+}
+
+func (s suite) testPutObjects(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	output := cs.PutObjects(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      "a.txt",
+		MimeType: cstorage.MimeTypeText,
+		Content:  "a",
+	}, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      "b.txt",
+		MimeType: cstorage.MimeTypeText,
+	})
+	assertEqual(t, "PutObjects() output length", len(output), 2)
+	assertEqual(t, "PutObjects() output key", output[0].Key, "a.txt")
+	assertNoError(t, "PutObjects() first output", output[0].Err)
+	assertEqual(t, "PutObjects() output key", output[1].Key, "b.txt")
+	assertError(t, "PutObjects() second output without content", output[1].Err)
+}
+
+func (s suite) testPutObjectBucketNotFound(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	err := cs.PutObject(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket + "-not-exists",
+		Key:      "object.txt",
+		MimeType: cstorage.MimeTypeText,
+		Content:  "content",
+	})
+	assertErrorIs(t, "PutObject() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testCopyObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	input := s.putObjectMetadata(t, cs, bucket, "dir/object.txt")
+	err := cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "dir/object.txt",
+		DstBucket: bucket,
+		DstKey:    "copy/object.txt",
+	})
+	assertNoError(t, "CopyObject()", err)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "copy/object.txt")
+	assertNoError(t, "GetObjectByKey() copy", err)
+	assertEqual(t, "GetObjectByKey() copy content", string(obj.Content), input.Content)
+	assertEqual(t, "GetObjectByKey() copy mime type", obj.MimeType, input.MimeType)
+	assertObjectMetadata(t, "GetObjectByKey() copy", obj, input)
+	exists, err := cs.ObjectExists(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "ObjectExists() source", err)
+	assertTrue(t, "ObjectExists() source is true", exists)
+	err = cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "not-exists.txt",
+		DstBucket: bucket,
+		DstKey:    "copy/not-exists.txt",
+	})
+	assertErrorIs(t, "CopyObject() with object not found", err, cstorage.ErrObjectNotFound)
+}
+
+func (s suite) testCopyObjectOtherBucket(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	dstBucket := s.bucketName()
+	err := cs.CreateBucket(ctx, s.createBucketInput(dstBucket))
+	assertNoError(t, "CreateBucket() destination", err)
+	t.Cleanup(func() {
+		ctx, cancel := s.context()
+		defer cancel()
+		_ = cs.DeleteObjectsByPrefix(ctx, cstorage.DeletePrefixInput{Bucket: dstBucket})
+		_ = cs.DeleteBucket(ctx, dstBucket)
+	})
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeText, "other bucket content")
+	err = cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "object.txt",
+		DstBucket: dstBucket,
+		DstKey:    "object.txt",
+	})
+	assertNoError(t, "CopyObject()", err)
+	obj, err := cs.GetObjectByKey(ctx, dstBucket, "object.txt")
+	assertNoError(t, "GetObjectByKey() copy", err)
+	assertEqual(t, "GetObjectByKey() copy content", string(obj.Content), "other bucket content")
+	err = cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "object.txt",
+		DstBucket: bucket + "-not-exists",
+		DstKey:    "object.txt",
+	})
+	assertErrorIs(t, "CopyObject() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testCopyObjectInvalid(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeText, "invalid content")
+	err := cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "object.txt",
+		DstBucket: bucket,
+		DstKey:    "object.txt",
+	})
+	assertErrorIs(t, "CopyObject() to itself", err, cstorage.ErrInvalidArgument)
+	err = cs.MoveObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "object.txt",
+		DstBucket: bucket,
+		DstKey:    "object.txt",
+	})
+	assertErrorIs(t, "MoveObject() to itself", err, cstorage.ErrInvalidArgument)
+	exists, err := cs.ObjectExists(ctx, bucket, "object.txt")
+	assertNoError(t, "ObjectExists()", err)
+	assertTrue(t, "ObjectExists() after MoveObject() to itself is true", exists)
+	err = cs.CopyObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "object.txt",
+		DstBucket: bucket,
+	})
+	assertErrorIs(t, "CopyObject() without destination key", err, cstorage.ErrInvalidArgument)
+}
+
+func (s suite) testMoveObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	input := s.putObjectMetadata(t, cs, bucket, "dir/object.txt")
+	err := cs.MoveObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "dir/object.txt",
+		DstBucket: bucket,
+		DstKey:    "moved/object.txt",
+	})
+	assertNoError(t, "MoveObject()", err)
+	obj, err := cs.GetObjectByKey(ctx, bucket, "moved/object.txt")
+	assertNoError(t, "GetObjectByKey() moved", err)
+	assertEqual(t, "GetObjectByKey() moved content", string(obj.Content), input.Content)
+	assertObjectMetadata(t, "GetObjectByKey() moved", obj, input)
+	exists, err := cs.ObjectExists(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "ObjectExists() source", err)
+	assertTrue(t, "ObjectExists() source is false", !exists)
+	err = cs.MoveObject(ctx, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "dir/object.txt",
+		DstBucket: bucket,
+		DstKey:    "moved/other.txt",
+	})
+	assertErrorIs(t, "MoveObject() with object not found", err, cstorage.ErrObjectNotFound)
+}
+
+func (s suite) testTransfer(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	input := s.putObjectMetadata(t, cs, bucket, "dir/object.txt")
+	other := s.memoryStorage(t, "other")
+	err := cstorage.Transfer(ctx, cs, other, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "dir/object.txt",
+		DstBucket: "other",
+		DstKey:    "object.txt",
+	})
+	assertNoError(t, "Transfer() to memory", err)
+	obj, err := other.GetObjectByKey(ctx, "other", "object.txt")
+	assertNoError(t, "GetObjectByKey() on memory", err)
+	assertEqual(t, "GetObjectByKey() on memory content", string(obj.Content), input.Content)
+	assertEqual(t, "GetObjectByKey() on memory mime type", obj.MimeType, input.MimeType)
+	assertObjectMetadata(t, "GetObjectByKey() on memory", obj, input)
+	err = cstorage.Transfer(ctx, other, cs, cstorage.CopyObjectInput{
+		SrcBucket: "other",
+		SrcKey:    "object.txt",
+		DstBucket: bucket,
+		DstKey:    "transferred/object.txt",
+	})
+	assertNoError(t, "Transfer() from memory", err)
+	obj, err = cs.GetObjectByKey(ctx, bucket, "transferred/object.txt")
+	assertNoError(t, "GetObjectByKey() transferred", err)
+	assertEqual(t, "GetObjectByKey() transferred content", string(obj.Content), input.Content)
+	assertObjectMetadata(t, "GetObjectByKey() transferred", obj, input)
+	err = cstorage.Transfer(ctx, cs, other, cstorage.CopyObjectInput{
+		SrcBucket: bucket,
+		SrcKey:    "not-exists.txt",
+		DstBucket: "other",
+		DstKey:    "not-exists.txt",
+	})
+	assertErrorIs(t, "Transfer() with object not found", err, cstorage.ErrObjectNotFound)
+}
+
+func (s suite) testMirror(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	other := s.memoryStorage(t, "other")
+	result, err := cstorage.Mirror(ctx, cs, other, cstorage.MirrorInput{
+		SrcBucket: bucket,
+		DstBucket: "other",
+		Prefix:    "dir/",
+	}, cstorage.NewOptsMirror().SetConcurrency(2))
+	assertNoError(t, "Mirror()", err)
+	var keys []string
+	for _, output := range result {
+		assertNoError(t, "Mirror() of "+output.Key, output.Err)
+		keys = append(keys, output.Key)
+	}
+	assertEqual(t, "Mirror() keys", keys, []string{"dir/b.txt", "dir/sub/c.txt"})
+	objs, err := other.ListObjects(ctx, "other")
+	assertNoError(t, "ListObjects() on memory", err)
+	assertEqual(t, "ListObjects() on memory keys", summaryKeys(objs), []string{"dir/b.txt", "dir/sub/c.txt"})
+	obj, err := other.GetObjectByKey(ctx, "other", "dir/sub/c.txt")
+	assertNoError(t, "GetObjectByKey() on memory", err)
+	assertEqual(t, "GetObjectByKey() on memory content", string(obj.Content), "dir/sub/c.txt")
+	_, err = cstorage.Mirror(ctx, cs, other, cstorage.MirrorInput{
+		SrcBucket: bucket + "-not-exists",
+		DstBucket: "other",
+	})
+	assertErrorIs(t, "Mirror() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testSyncUp(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	localDir := t.TempDir()
+	writeFile(t, filepath.Join(localDir, "index.html"), "<html></html>")
+	writeFile(t, filepath.Join(localDir, "css", "style.css"), "body {}")
+	result, err := cstorage.SyncUp(ctx, cs, localDir, bucket, "site")
+	assertNoError(t, "SyncUp()", err)
+	assertEqual(t, "SyncUp() actions", syncActions(t, result), []string{"upload site/css/style.css",
+		"upload site/index.html"})
+	obj, err := cs.GetObjectByKey(ctx, bucket, "site/index.html")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() content", string(obj.Content), "<html></html>")
+	assertEqual(t, "GetObjectByKey() mime type", obj.MimeType, cstorage.MimeTypeHtml)
+	result, err = cstorage.SyncUp(ctx, cs, localDir, bucket, "site", cstorage.NewOptsSync().SetChecksum(true))
+	assertNoError(t, "SyncUp() without changes", err)
+	assertEqual(t, "SyncUp() without changes actions", syncActions(t, result), []string(nil))
+	writeFile(t, filepath.Join(localDir, "index.html"), "<html>changed</html>")
+	s.putObject(t, cs, bucket, "site/old.txt", cstorage.MimeTypeText, "old")
+	opts := cstorage.NewOptsSync().SetDelete(true)
+	result, err = cstorage.SyncUp(ctx, cs, localDir, bucket, "site", opts.SetDryRun(true))
+	assertNoError(t, "SyncUp() dry run", err)
+	assertEqual(t, "SyncUp() dry run actions", syncActions(t, result), []string{"upload site/index.html",
+		"delete site/old.txt"})
+	exists, err := cs.ObjectExists(ctx, bucket, "site/old.txt")
+	assertNoError(t, "ObjectExists() after dry run", err)
+	assertTrue(t, "ObjectExists() after dry run is true", exists)
+	result, err = cstorage.SyncUp(ctx, cs, localDir, bucket, "site", opts.SetDryRun(false))
+	assertNoError(t, "SyncUp() with delete", err)
+	assertEqual(t, "SyncUp() with delete actions", syncActions(t, result), []string{"upload site/index.html",
+		"delete site/old.txt"})
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects()", err)
+	assertEqual(t, "ListObjects() keys", summaryKeys(objs), []string{"site/css/style.css", "site/index.html"})
+	obj, err = cs.GetObjectByKey(ctx, bucket, "site/index.html")
+	assertNoError(t, "GetObjectByKey() changed", err)
+	assertEqual(t, "GetObjectByKey() changed content", string(obj.Content), "<html>changed</html>")
+}
+
+func (s suite) testSyncDown(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	localDir := filepath.Join(t.TempDir(), "down")
+	result, err := cstorage.SyncDown(ctx, cs, localDir, bucket, "dir/")
+	assertNoError(t, "SyncDown()", err)
+	assertEqual(t, "SyncDown() actions", syncActions(t, result), []string{"download dir/b.txt",
+		"download dir/sub/c.txt"})
+	assertEqual(t, "SyncDown() content", readFile(t, filepath.Join(localDir, "sub", "c.txt")), "dir/sub/c.txt")
+	result, err = cstorage.SyncDown(ctx, cs, localDir, bucket, "dir/")
+	assertNoError(t, "SyncDown() without changes", err)
+	assertEqual(t, "SyncDown() without changes actions", syncActions(t, result), []string(nil))
+	s.putObject(t, cs, bucket, "dir/b.txt", cstorage.MimeTypeText, "changed")
+	writeFile(t, filepath.Join(localDir, "old.txt"), "old")
+	result, err = cstorage.SyncDown(ctx, cs, localDir, bucket, "dir/", cstorage.NewOptsSync().SetDelete(true))
+	assertNoError(t, "SyncDown() with delete", err)
+	assertEqual(t, "SyncDown() with delete actions", syncActions(t, result), []string{"download dir/b.txt",
+		"delete dir/old.txt"})
+	assertEqual(t, "SyncDown() changed content", readFile(t, filepath.Join(localDir, "b.txt")), "changed")
+	_, err = os.Stat(filepath.Join(localDir, "old.txt"))
+	assertTrue(t, "SyncDown() removed the extraneous file", os.IsNotExist(err))
+}
+
+func (s suite) testGetObjectByKeyNotFound(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	obj, err := cs.GetObjectByKey(ctx, bucket, "not-exists.txt")
+	assertErrorIs(t, "GetObjectByKey() with object not found", err, cstorage.ErrObjectNotFound)
+	assertTrue(t, "GetObjectByKey() with object not found returns nil", helper.IsNil(obj))
+	_, err = cs.GetObjectByKey(ctx, bucket+"-not-exists", "not-exists.txt")
+	assertErrorIs(t, "GetObjectByKey() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testGetObjectReader(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "dir/object.txt", cstorage.MimeTypeText, "reader content")
+	reader, obj, err := cs.GetObjectReader(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "GetObjectReader()", err)
+	if t.Failed() {
+		return
+	}
+	defer reader.Close()
+	assertEqual(t, "GetObjectReader() key", obj.Key, "dir/object.txt")
+	assertEqual(t, "GetObjectReader() mime type", obj.MimeType, cstorage.MimeTypeText)
+	assertEqual(t, "GetObjectReader() size", obj.Size, int64(len("reader content")))
+	assertEqual(t, "GetObjectReader() url", obj.Url, cs.GetObjectUrl(bucket, "dir/object.txt"))
+	assertTrue(t, "GetObjectReader() content is empty", helper.IsEmpty(obj.Content))
+	bs, err := io.ReadAll(reader)
+	assertNoError(t, "GetObjectReader() read", err)
+	assertEqual(t, "GetObjectReader() content", string(bs), "reader content")
+}
+
+func (s suite) testGetObjectReaderNotFound(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	reader, obj, err := cs.GetObjectReader(ctx, bucket, "not-exists.txt")
+	assertErrorIs(t, "GetObjectReader() with object not found", err, cstorage.ErrObjectNotFound)
+	assertTrue(t, "GetObjectReader() reader is nil", helper.IsNil(reader))
+	assertTrue(t, "GetObjectReader() object is nil", helper.IsNil(obj))
+	_, _, err = cs.GetObjectReader(ctx, bucket+"-not-exists", "object.txt")
+	assertErrorIs(t, "GetObjectReader() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testGetObjectRangeReader(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	content := "0123456789abcdefghij"
+	s.putObject(t, cs, bucket, "range.txt", cstorage.MimeTypeText, content)
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+		want   string
+	}{
+		{name: "offset and length", offset: 5, length: 5, want: "56789"},
+		{name: "length after the end", offset: 15, length: 10, want: "fghij"},
+		{name: "until the end", offset: 10, length: -1, want: "abcdefghij"},
+		{name: "whole object", offset: 0, length: -1, want: content},
+		{name: "last bytes", offset: -4, length: -1, want: "ghij"},
+		{name: "last bytes bigger than the object", offset: -100, length: -1, want: content},
+	}
+	for _, tt := range tests {
+		reader, obj, err := cs.GetObjectRangeReader(ctx, bucket, "range.txt", tt.offset, tt.length)
+		assertNoError(t, "GetObjectRangeReader() "+tt.name, err)
+		if helper.IsNotNil(err) {
+			continue
+		}
+		bs, err := io.ReadAll(reader)
+		_ = reader.Close()
+		assertNoError(t, "GetObjectRangeReader() read "+tt.name, err)
+		assertEqual(t, "GetObjectRangeReader() content "+tt.name, string(bs), tt.want)
+		assertEqual(t, "GetObjectRangeReader() size "+tt.name, obj.Size, int64(len(content)))
+		assertEqual(t, "GetObjectRangeReader() mime type "+tt.name, obj.MimeType, cstorage.MimeTypeText)
+	}
+}
+
+func (s suite) testGetObjectRangeReaderInvalid(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "range.txt", cstorage.MimeTypeText, "0123456789")
+	_, _, err := cs.GetObjectRangeReader(ctx, bucket, "range.txt", 0, 0)
+	assertErrorIs(t, "GetObjectRangeReader() with zero length", err, cstorage.ErrInvalidArgument)
+	_, _, err = cs.GetObjectRangeReader(ctx, bucket, "range.txt", -5, 2)
+	assertErrorIs(t, "GetObjectRangeReader() with last bytes and length", err, cstorage.ErrInvalidArgument)
+	_, _, err = cs.GetObjectRangeReader(ctx, bucket, "range.txt", 10, 5)
+	assertErrorIs(t, "GetObjectRangeReader() with offset at the end", err, cstorage.ErrInvalidArgument)
+	_, _, err = cs.GetObjectRangeReader(ctx, bucket, "range.txt", 20, -1)
+	assertErrorIs(t, "GetObjectRangeReader() with offset past the end", err, cstorage.ErrInvalidArgument)
+	_, _, err = cs.GetObjectRangeReader(ctx, bucket, "not-exists.txt", 0, 5)
+	assertErrorIs(t, "GetObjectRangeReader() with object not found", err, cstorage.ErrObjectNotFound)
+}
+
+func (s suite) testStatObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "dir/object.txt", cstorage.MimeTypeText, "stat content")
+	obj, err := cs.StatObject(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "StatObject()", err)
+	assertEqual(t, "StatObject() key", obj.Key, "dir/object.txt")
+	assertEqual(t, "StatObject() mime type", obj.MimeType, cstorage.MimeTypeText)
+	assertEqual(t, "StatObject() size", obj.Size, int64(len("stat content")))
+	assertEqual(t, "StatObject() url", obj.Url, cs.GetObjectUrl(bucket, "dir/object.txt"))
+	assertTrue(t, "StatObject() content is empty", helper.IsEmpty(obj.Content))
+	assertTrue(t, "StatObject() etag is not empty", helper.IsNotEmpty(obj.ETag))
+	assertTrue(t, "StatObject() last modified is not empty", !obj.LastModifiedAt.IsZero())
+	got, err := cs.GetObjectByKey(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "GetObjectByKey()", err)
+	assertEqual(t, "GetObjectByKey() etag", got.ETag, obj.ETag)
+	s.putObject(t, cs, bucket, "dir/object.txt", cstorage.MimeTypeText, "other content")
+	overwritten, err := cs.StatObject(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "StatObject() after overwrite", err)
+	assertTrue(t, "StatObject() etag changed after overwrite", overwritten.ETag != obj.ETag)
+	_, err = cs.StatObject(ctx, bucket, "not-exists.txt")
+	assertErrorIs(t, "StatObject() with object not found", err, cstorage.ErrObjectNotFound)
+	_, err = cs.StatObject(ctx, bucket+"-not-exists", "dir/object.txt")
+	assertErrorIs(t, "StatObject() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testObjectExists(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "dir/object.txt", cstorage.MimeTypeText, "exists content")
+	exists, err := cs.ObjectExists(ctx, bucket, "dir/object.txt")
+	assertNoError(t, "ObjectExists()", err)
+	assertTrue(t, "ObjectExists() is true", exists)
+	exists, err = cs.ObjectExists(ctx, bucket, "not-exists.txt")
+	assertNoError(t, "ObjectExists() with object not found", err)
+	assertTrue(t, "ObjectExists() with object not found is false", !exists)
+	_, err = cs.ObjectExists(ctx, bucket+"-not-exists", "dir/object.txt")
+	assertErrorIs(t, "ObjectExists() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testBucketExists(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	exists, err := cs.BucketExists(ctx, bucket)
+	assertNoError(t, "BucketExists()", err)
+	assertTrue(t, "BucketExists() is true", exists)
+	exists, err = cs.BucketExists(ctx, bucket+"-not-exists")
+	assertNoError(t, "BucketExists() with bucket not found", err)
+	assertTrue(t, "BucketExists() with bucket not found is false", !exists)
+}
+
+func (s suite) testGetObjectUrl(t *testing.T) {
+	cs, bucket := s.setup(t)
+	assertTrue(t, "GetObjectUrl() is not empty", helper.IsNotEmpty(cs.GetObjectUrl(bucket, "dir/object.txt")))
+	assertTrue(t, "GetObjectUrl() is unique per key",
+		cs.GetObjectUrl(bucket, "dir/a.txt") != cs.GetObjectUrl(bucket, "dir/b.txt"))
+}
+
+func (s suite) testPresignObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	getUrl, err := cs.PresignGetObject(ctx, bucket, "dir/object.txt", cstorage.NewOptsPresign().SetExpires(time.Hour))
+	if errors.Is(err, cstorage.ErrNotSupported) {
+		t.Skip("presigned urls are not supported by the provider")
+	}
+	assertNoError(t, "PresignGetObject()", err)
+	assertTrue(t, "PresignGetObject() is not empty", helper.IsNotEmpty(getUrl))
+	putUrl, err := cs.PresignPutObject(ctx, bucket, "dir/object.txt", cstorage.NewOptsPresign().SetExpires(time.Hour))
+	assertNoError(t, "PresignPutObject()", err)
+	assertTrue(t, "PresignPutObject() is not empty", helper.IsNotEmpty(putUrl))
+	assertTrue(t, "PresignPutObject() is different of PresignGetObject()", getUrl != putUrl)
+}
+
+func (s suite) testGeneratePostPolicy(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	opt := cstorage.NewOptsPostPolicy().SetContentType(cstorage.MimeTypeText).SetContentLengthRange(1, 1<<20)
+	policy, err := cs.GeneratePostPolicy(ctx, cstorage.PostPolicyInput{Bucket: bucket, KeyPrefix: "uploads/"}, opt)
+	if errors.Is(err, cstorage.ErrNotSupported) {
+		t.Skip("post policies are not supported by the provider")
+	}
+	assertNoError(t, "GeneratePostPolicy()", err)
+	assertTrue(t, "GeneratePostPolicy() url is not empty", helper.IsNotEmpty(policy.Url))
+	assertTrue(t, "GeneratePostPolicy() key field has the prefix", strings.HasPrefix(policy.Fields["key"], "uploads/"))
+	_, err = cs.GeneratePostPolicy(ctx, cstorage.PostPolicyInput{Bucket: bucket}, opt)
+	assertErrorIs(t, "GeneratePostPolicy() without key", err, cstorage.ErrInvalidArgument)
+}
+
+func (s suite) testListObjects(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects() with empty bucket", err)
+	assertEqual(t, "ListObjects() with empty bucket length", len(objs), 0)
+	s.putTree(t, cs, bucket)
+	objs, err = cs.ListObjects(ctx, bucket, nil)
+	assertNoError(t, "ListObjects()", err)
+	assertEqual(t, "ListObjects() keys", summaryKeys(objs), treeKeys())
+	for _, obj := range objs {
+		assertEqual(t, "ListObjects() size of "+obj.Key, obj.Size, int64(len(obj.Key)))
+		assertEqual(t, "ListObjects() url of "+obj.Key, obj.Url, cs.GetObjectUrl(bucket, obj.Key))
+		assertTrue(t, "ListObjects() last modified of "+obj.Key+" is not empty", !obj.LastModifiedAt.IsZero())
+	}
+}
+
+func (s suite) testListObjectsPrefix(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	objs, err := cs.ListObjects(ctx, bucket, cstorage.NewOptsListObjects().SetPrefix("dir/"))
+	assertNoError(t, "ListObjects() with prefix", err)
+	assertEqual(t, "ListObjects() with prefix keys", summaryKeys(objs), []string{"dir/b.txt", "dir/sub/c.txt"})
+	objs, err = cs.ListObjects(ctx, bucket, cstorage.NewOptsListObjects().SetPrefix("not-exists/"))
+	assertNoError(t, "ListObjects() with prefix not found", err)
+	assertEqual(t, "ListObjects() with prefix not found length", len(objs), 0)
+}
+
+func (s suite) testListObjectsDelimiter(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	objs, err := cs.ListObjects(ctx, bucket, cstorage.NewOptsListObjects().SetDelimiter("/"))
+	assertNoError(t, "ListObjects() with delimiter", err)
+	assertEqual(t, "ListObjects() with delimiter keys", summaryKeys(objs), []string{"a.txt", "dir.txt", "dir/"})
+	assertEqual(t, "ListObjects() with delimiter prefixes", summaryPrefixes(objs), []bool{false, false, true})
+	objs, err = cs.ListObjects(ctx, bucket, cstorage.NewOptsListObjects().SetPrefix("dir/").SetDelimiter("/"))
+	assertNoError(t, "ListObjects() with prefix and delimiter", err)
+	assertEqual(t, "ListObjects() with prefix and delimiter keys", summaryKeys(objs),
+		[]string{"dir/b.txt", "dir/sub/"})
+	assertEqual(t, "ListObjects() with prefix and delimiter prefixes", summaryPrefixes(objs), []bool{false, true})
+	objs, err = cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects()", err)
+	assertEqual(t, "ListObjects() prefixes", summaryPrefixes(objs), []bool{false, false, false, false})
+}
+
+func (s suite) testListObjectsPage(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	page, err := cs.ListObjectsPage(ctx, bucket, cstorage.NewOptsListObjects().SetMaxResults(2))
+	assertNoError(t, "ListObjectsPage()", err)
+	assertTrue(t, "ListObjectsPage() next page token is not empty", helper.IsNotEmpty(page.NextPageToken))
+	assertTrue(t, "ListObjectsPage() prefixes is empty", helper.IsEmpty(page.Prefixes))
+	keys, _ := s.listAllPages(t, cs, bucket, cstorage.NewOptsListObjects().SetMaxResults(2))
+	assertEqual(t, "ListObjectsPage() keys of all pages", keys, []string{"a.txt", "dir.txt", "dir/b.txt",
+		"dir/sub/c.txt"})
+	keys, _ = s.listAllPages(t, cs, bucket, cstorage.NewOptsListObjects().SetPrefix("dir/").SetMaxResults(1))
+	assertEqual(t, "ListObjectsPage() with prefix keys of all pages", keys, []string{"dir/b.txt", "dir/sub/c.txt"})
+	_, err = cs.ListObjectsPage(ctx, bucket+"-not-exists")
+	assertErrorIs(t, "ListObjectsPage() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testListObjectsPageDelimiter(t *testing.T) {
+	cs, bucket := s.setup(t)
+	s.putTree(t, cs, bucket)
+	keys, prefixes := s.listAllPages(t, cs, bucket, cstorage.NewOptsListObjects().SetDelimiter("/").SetMaxResults(1))
+	assertEqual(t, "ListObjectsPage() with delimiter keys", keys, []string{"a.txt", "dir.txt"})
+	assertEqual(t, "ListObjectsPage() with delimiter prefixes", prefixes, []string{"dir/"})
+	keys, prefixes = s.listAllPages(t, cs, bucket, cstorage.NewOptsListObjects().SetPrefix("dir/").SetDelimiter("/").
+		SetMaxResults(1))
+	assertEqual(t, "ListObjectsPage() with prefix and delimiter keys", keys, []string{"dir/b.txt"})
+	assertEqual(t, "ListObjectsPage() with prefix and delimiter prefixes", prefixes, []string{"dir/sub/"})
+}
+
+func (s suite) testListObjectsBucketNotFound(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := cs.ListObjects(ctx, bucket+"-not-exists")
+	assertErrorIs(t, "ListObjects() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testDeleteObject(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	err := cs.DeleteObject(ctx, cstorage.DeleteObjectInput{Bucket: bucket, Key: "dir/b.txt"})
+	assertNoError(t, "DeleteObject()", err)
+	_, err = cs.GetObjectByKey(ctx, bucket, "dir/b.txt")
+	assertErrorIs(t, "GetObjectByKey() after DeleteObject()", err, cstorage.ErrObjectNotFound)
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects() after DeleteObject()", err)
+	assertEqual(t, "ListObjects() after DeleteObject() keys", summaryKeys(objs),
+		[]string{"a.txt", "dir.txt", "dir/sub/c.txt"})
+}
+
+func (s suite) testDeleteObjects(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	output := cs.DeleteObjects(ctx, cstorage.DeleteObjectInput{Bucket: bucket, Key: "a.txt"},
+		cstorage.DeleteObjectInput{Bucket: bucket, Key: "dir/sub/c.txt"})
+	assertEqual(t, "DeleteObjects() output length", len(output), 2)
+	for _, item := range output {
+		assertEqual(t, "DeleteObjects() output bucket", item.Bucket, bucket)
+		assertNoError(t, "DeleteObjects() output of "+item.Key, item.Err)
+	}
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects() after DeleteObjects()", err)
+	assertEqual(t, "ListObjects() after DeleteObjects() keys", summaryKeys(objs), []string{"dir.txt", "dir/b.txt"})
+}
+
+func (s suite) testDeleteObjectsByPrefix(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	err := cs.DeleteObjectsByPrefix(ctx, cstorage.DeletePrefixInput{Bucket: bucket, Prefix: "dir/"})
+	assertNoError(t, "DeleteObjectsByPrefix()", err)
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects() after DeleteObjectsByPrefix()", err)
+	assertEqual(t, "ListObjects() after DeleteObjectsByPrefix() keys", summaryKeys(objs),
+		[]string{"a.txt", "dir.txt"})
+	err = cs.DeleteObjectsByPrefix(ctx, cstorage.DeletePrefixInput{Bucket: bucket + "-not-exists", Prefix: "dir/"})
+	assertErrorIs(t, "DeleteObjectsByPrefix() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testDeleteObjectsByPrefixes(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putTree(t, cs, bucket)
+	output := cs.DeleteObjectsByPrefixes(ctx, cstorage.DeletePrefixInput{Bucket: bucket, Prefix: "dir/sub/"},
+		cstorage.DeletePrefixInput{Bucket: bucket, Prefix: "a"})
+	assertEqual(t, "DeleteObjectsByPrefixes() output length", len(output), 2)
+	for _, item := range output {
+		assertNoError(t, "DeleteObjectsByPrefixes() output of "+item.Prefix, item.Err)
+	}
+	objs, err := cs.ListObjects(ctx, bucket)
+	assertNoError(t, "ListObjects() after DeleteObjectsByPrefixes()", err)
+	assertEqual(t, "ListObjects() after DeleteObjectsByPrefixes() keys", summaryKeys(objs),
+		[]string{"dir.txt", "dir/b.txt"})
+}
+
+func (s suite) testDeleteBucket(t *testing.T) {
+	cs := s.factory()
+	defer cs.SimpleDisconnect()
+	ctx, cancel := s.context()
+	defer cancel()
+	bucket := s.bucketName()
+	err := cs.CreateBucket(ctx, s.createBucketInput(bucket))
+	assertNoError(t, "CreateBucket()", err)
+	err = cs.DeleteBucket(ctx, bucket)
+	assertNoError(t, "DeleteBucket()", err)
+	_, err = cs.ListObjects(ctx, bucket)
+	assertErrorIs(t, "ListObjects() after DeleteBucket()", err, cstorage.ErrBucketNotFound)
+	err = cs.DeleteBucket(ctx, bucket)
+	assertErrorIs(t, "DeleteBucket() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testDeleteBucketNotEmpty(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	s.putObject(t, cs, bucket, "object.txt", cstorage.MimeTypeText, "content")
+	err := cs.DeleteBucket(ctx, bucket)
+	assertErrorIs(t, "DeleteBucket() with bucket not empty", err, cstorage.ErrBucketNotEmpty)
+	_, err = cs.GetObjectByKey(ctx, bucket, "object.txt")
+	assertNoError(t, "GetObjectByKey() after DeleteBucket() with bucket not empty", err)
+}
+
+func (s suite) testDeleteBuckets(t *testing.T) {
+	cs := s.factory()
+	defer cs.SimpleDisconnect()
+	ctx, cancel := s.context()
+	defer cancel()
+	bucket := s.bucketName()
+	err := cs.CreateBucket(ctx, s.createBucketInput(bucket))
+	assertNoError(t, "CreateBucket()", err)
+	output := cs.DeleteBuckets(ctx, bucket, bucket+"-not-exists")
+	assertEqual(t, "DeleteBuckets() output length", len(output), 2)
+	assertEqual(t, "DeleteBuckets() output bucket", output[0].Bucket, bucket)
+	assertNoError(t, "DeleteBuckets() first output", output[0].Err)
+	assertEqual(t, "DeleteBuckets() output bucket", output[1].Bucket, bucket+"-not-exists")
+	assertErrorIs(t, "DeleteBuckets() second output with bucket not found", output[1].Err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testBucketHandle(t *testing.T) {
+	cs, bucket := s.setup(t)
+	ctx, cancel := s.context()
+	defer cancel()
+	h := cs.Bucket(bucket)
+	assertEqual(t, "Bucket() name", h.Name(), bucket)
+	exists, err := h.Exists(ctx)
+	assertNoError(t, "Exists()", err)
+	assertTrue(t, "Exists() of created bucket", exists)
+	for _, key := range treeKeys() {
+		err = h.Put(ctx, cstorage.PutObjectInput{Key: key, MimeType: cstorage.MimeTypeText, Content: key})
+		assertNoError(t, "Put() of "+key, err)
+	}
+	obj, err := h.Get(ctx, "dir/b.txt")
+	assertNoError(t, "Get()", err)
+	assertEqual(t, "Get() content", string(obj.Content), "dir/b.txt")
+	obj, err = h.Stat(ctx, "a.txt")
+	assertNoError(t, "Stat()", err)
+	assertEqual(t, "Stat() size", obj.Size, int64(len("a.txt")))
+	assertEqual(t, "Url()", h.Url("a.txt"), cs.GetObjectUrl(bucket, "a.txt"))
+	err = h.Copy(ctx, "a.txt", "copy.txt")
+	assertNoError(t, "Copy()", err)
+	exists, err = h.ObjectExists(ctx, "copy.txt")
+	assertNoError(t, "ObjectExists()", err)
+	assertTrue(t, "ObjectExists() of copied object", exists)
+	err = h.Delete(ctx, "a.txt", "copy.txt")
+	assertNoError(t, "Delete()", err)
+	err = h.DeletePrefix(ctx, "dir/")
+	assertNoError(t, "DeletePrefix()", err)
+	objs, err := h.List(ctx)
+	assertNoError(t, "List()", err)
+	assertEqual(t, "List() keys", summaryKeys(objs), []string{"dir.txt"})
+	err = cs.Bucket(bucket+"-not-exists").Put(ctx, cstorage.PutObjectInput{Key: "a.txt", Content: "a.txt"})
+	assertErrorIs(t, "Put() with bucket not found", err, cstorage.ErrBucketNotFound)
+}
+
+func (s suite) testDisconnect(t *testing.T) {
+	cs := s.factory()
+	err := cs.Disconnect()
+	assertNoError(t, "Disconnect()", err)
+}
+
+// setup creates a new instance and bucket, both are removed when the test and all its subtests complete
+func (s suite) setup(t *testing.T) (cstorage.CStorage, string) {
+	cs := s.factory()
+	if helper.IsNil(cs) {
+		logger.Error("factory returned a nil instance")
+		t.FailNow()
+	}
+	ctx, cancel := s.context()
+	defer cancel()
+	bucket := s.bucketName()
+	err := cs.CreateBucket(ctx, s.createBucketInput(bucket))
+	assertNoError(t, "CreateBucket()", err)
+	t.Cleanup(func() {
+		ctx, cancel := s.context()
+		defer cancel()
+		_ = cs.DeleteObjectsByPrefix(ctx, cstorage.DeletePrefixInput{Bucket: bucket})
+		_ = cs.DeleteBucket(ctx, bucket)
+		cs.SimpleDisconnect()
+	})
+	return cs, bucket
+}
+
+// memoryStorage returns a memory storage with the bucket created, used as the other side of the transfers
+func (s suite) memoryStorage(t *testing.T, bucket string) cstorage.CStorage {
+	ctx, cancel := s.context()
+	defer cancel()
+	cs := cstorage.NewMemoryStorage()
+	err := cs.CreateBucket(ctx, cstorage.CreateBucketInput{Bucket: bucket})
+	assertNoError(t, "CreateBucket() on memory", err)
+	return cs
+}
+
+func (s suite) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.TODO(), s.opts.Timeout)
+}
+
+func (s suite) bucketName() string {
+	return fmt.Sprintf("%s-%d-%d", s.opts.BucketPrefix, time.Now().UnixMilli(), bucketSequence.Add(1))
+}
+
+func (s suite) createBucketInput(bucket string) cstorage.CreateBucketInput {
+	return cstorage.CreateBucketInput{
+		Bucket:    bucket,
+		ProjectId: s.opts.ProjectId,
+		Location:  s.opts.Location,
+	}
+}
+
+func (s suite) putObject(t *testing.T, cs cstorage.CStorage, bucket, key string, mimeType cstorage.MimeType,
+	content any) {
+	ctx, cancel := s.context()
+	defer cancel()
+	err := cs.PutObject(ctx, cstorage.PutObjectInput{
+		Bucket:   bucket,
+		Key:      key,
+		MimeType: mimeType,
+		Content:  content,
+	})
+	assertNoError(t, "PutObject() of "+key, err)
+}
+
+func (s suite) putObjectMetadata(t *testing.T, cs cstorage.CStorage, bucket, key string) cstorage.PutObjectInput {
+	ctx, cancel := s.context()
+	defer cancel()
+	input := cstorage.PutObjectInput{
+		Bucket:             bucket,
+		Key:                key,
+		MimeType:           cstorage.MimeTypeText,
+		Content:            "metadata content",
+		Metadata:           map[string]string{"uploader": "foo-bar", "filename": "original.txt"},
+		CacheControl:       "no-cache",
+		ContentDisposition: `attachment; filename="original.txt"`,
+		ContentEncoding:    "identity",
+		ContentLanguage:    "en-US",
+	}
+	err := cs.PutObject(ctx, input)
+	assertNoError(t, "PutObject()", err)
+	return input
+}
+
+// putTree puts the objects of treeKeys, the content of each object is its own key
+func (s suite) putTree(t *testing.T, cs cstorage.CStorage, bucket string) {
+	for _, key := range treeKeys() {
+		s.putObject(t, cs, bucket, key, cstorage.MimeTypeText, key)
+	}
+}
+
+func treeKeys() []string {
+	return []string{"a.txt", "dir.txt", "dir/b.txt", "dir/sub/c.txt"}
+}
+
+// listAllPages lists all the pages with the opts, returning the keys and the prefixes of all of them
+func (s suite) listAllPages(t *testing.T, cs cstorage.CStorage, bucket string, opts *cstorage.OptsListObjects) (
+	[]string, []string) {
+	t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	keys := []string{}
+	prefixes := []string{}
+	for i := 0; i < 100; i++ {
+		page, err := cs.ListObjectsPage(ctx, bucket, opts)
+		assertNoError(t, "ListObjectsPage()", err)
+		assertTrue(t, "ListObjectsPage() page size", len(page.Items)+len(page.Prefixes) <= opts.MaxResults)
+		keys = append(keys, summaryKeys(page.Items)...)
+		prefixes = append(prefixes, page.Prefixes...)
+		if helper.IsEmpty(page.NextPageToken) {
+			return keys, prefixes
+		}
+		opts.SetPageToken(page.NextPageToken)
+	}
+	logger.Errorf("ListObjectsPage() does not finish the pages")
+	t.FailNow()
+	return nil, nil
+}
+
+func summaryPrefixes(objs []cstorage.ObjectSummary) []bool {
+	prefixes := []bool{}
+	for _, obj := range objs {
+		prefixes = append(prefixes, obj.IsPrefix)
+	}
+	return prefixes
+}
+
+func summaryKeys(objs []cstorage.ObjectSummary) []string {
+	keys := []string{}
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func assertNoError(t *testing.T, name string, err error) {
+	t.Helper()
+	if helper.IsNotNil(err) {
+		logger.Errorf("%s err = %v, want nil", name, err)
+		t.FailNow()
+	}
+}
+
+func assertError(t *testing.T, name string, err error) {
+	t.Helper()
+	if helper.IsNil(err) {
+		logger.Errorf("%s err = nil, want error", name)
+		t.Fail()
+	}
+}
+
+func assertErrorIs(t *testing.T, name string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		logger.Errorf("%s err = %v, want %v", name, err, target)
+		t.Fail()
+	}
+}
+
+func assertEqual(t *testing.T, name string, got, want any) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		logger.Errorf("%s = %v, want %v", name, got, want)
+		t.FailNow()
+	}
+}
+
+func assertTrue(t *testing.T, name string, ok bool) {
+	t.Helper()
+	if !ok {
+		logger.Errorf("%s = false, want true", name)
+		t.Fail()
+	}
+}
+
+func assertObjectMetadata(t *testing.T, name string, obj *cstorage.Object, input cstorage.PutObjectInput) {
+	assertEqual(t, name+" metadata", obj.Metadata, input.Metadata)
+	assertEqual(t, name+" cache control", obj.CacheControl, input.CacheControl)
+	assertEqual(t, name+" content disposition", obj.ContentDisposition, input.ContentDisposition)
+	assertEqual(t, name+" content encoding", obj.ContentEncoding, input.ContentEncoding)
+	assertEqual(t, name+" content language", obj.ContentLanguage, input.ContentLanguage)
+}
+
+// syncActions returns the action and the key of each output, failing the test when any of them has an error
+func syncActions(t *testing.T, result []cstorage.SyncOutput) []string {
+	var actions []string
+	for _, output := range result {
+		assertNoError(t, "SyncOutput of "+output.Key, output.Err)
+		actions = append(actions, string(output.Action)+" "+output.Key)
+	}
+	return actions
+}
+
+func writeFile(t *testing.T, filePath, content string) {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if helper.IsNil(err) {
+		err = os.WriteFile(filePath, []byte(content), 0644)
+	}
+	assertNoError(t, "WriteFile() of "+filePath, err)
+}
+
+func readFile(t *testing.T, filePath string) string {
+	bs, err := os.ReadFile(filePath)
+	assertNoError(t, "ReadFile() of "+filePath, err)
+	return string(bs)
+}
@@ -0,0 +1,38 @@
+package cstoragetest
+
+import (
+	"github.com/GabrielHCataldo/go-cloud-storage/cstorage"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"testing"
+	"time"
+)
+
+func TestRunConformanceMemory(t *testing.T) {
+	RunConformance(t, func() cstorage.CStorage {
+		return cstorage.NewMemoryStorage()
+	})
+}
+
+func TestRunConformanceMemoryWithOptions(t *testing.T) {
+	RunConformance(t, func() cstorage.CStorage {
+		return cstorage.NewMemoryStorage(
+			cstorage.WithTimeout(5*time.Second),
+			cstorage.WithRetry(2, 10*time.Millisecond),
+			cstorage.WithConcurrency(2),
+			cstorage.WithLogger(nil),
+		)
+	})
+}
+
+func TestRunConformanceLocal(t *testing.T) {
+	rootDir := t.TempDir()
+	RunConformance(t, func() cstorage.CStorage {
+		cs, err := cstorage.NewLocalStorage(rootDir)
+		if helper.IsNotNil(err) {
+			logger.Error("error start local storage:", err)
+			return nil
+		}
+		return cs
+	})
+}
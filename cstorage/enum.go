@@ -25,3 +25,13 @@ const (
 func (f MimeType) String() string {
 	return string(f)
 }
+
+// SyncAction action taken by SyncUp and SyncDown on each changed file
+type SyncAction string
+
+//goland:noinspection GoUnusedConst
+const (
+	SyncActionUpload   SyncAction = "upload"
+	SyncActionDownload SyncAction = "download"
+	SyncActionDelete   SyncAction = "delete"
+)
@@ -0,0 +1,186 @@
+package cstorage
+
+import (
+	"cloud.google.com/go/storage"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/aws/smithy-go"
+	"google.golang.org/api/googleapi"
+	"net/http"
+	"strings"
+)
+
+// The errors returned by all the providers are wrapped by one of these errors, so they can be checked with
+// errors.Is without importing the provider SDK, the original error can still be reached using errors.As.
+// Unlike the rest of the package, they are created by the standard errors package, because the go-errors package
+// adds the debug stack to the message and its Is function compares the messages without unwrapping the errors.
+var (
+	// ErrObjectNotFound the object does not exist in the bucket
+	ErrObjectNotFound = errors.New("cstorage: object not found")
+	// ErrBucketNotFound the bucket does not exist
+	ErrBucketNotFound = errors.New("cstorage: bucket not found")
+	// ErrBucketAlreadyExists the bucket name is already in use
+	ErrBucketAlreadyExists = errors.New("cstorage: bucket already exists")
+	// ErrBucketNotEmpty the bucket can't be deleted because it has objects
+	ErrBucketNotEmpty = errors.New("cstorage: bucket is not empty")
+	// ErrPermissionDenied the credentials are invalid or don't have permission for the operation
+	ErrPermissionDenied = errors.New("cstorage: permission denied")
+	// ErrPreconditionFailed a condition of the request, such as the generation or ETag, was not met
+	ErrPreconditionFailed = errors.New("cstorage: precondition failed")
+	// ErrInvalidArgument a parameter of the request is invalid, such as the bucket name, the key or the range
+	ErrInvalidArgument = errors.New("cstorage: invalid argument")
+	// ErrNotSupported the operation or one of its options is not supported by the provider
+	ErrNotSupported = errors.New("cstorage: not supported")
+)
+
+var cstorageErrors = []error{
+	ErrObjectNotFound,
+	ErrBucketNotFound,
+	ErrBucketAlreadyExists,
+	ErrBucketNotEmpty,
+	ErrPermissionDenied,
+	ErrPreconditionFailed,
+	ErrInvalidArgument,
+	ErrNotSupported,
+}
+
+// wrapError wraps err with the cstorage error, keeping the original error reachable by errors.Is and errors.As
+func wrapError(cstorageErr, err error) error {
+	if helper.IsNil(err) {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", cstorageErr, err)
+}
+
+func isCStorageError(err error) bool {
+	for _, cstorageErr := range cstorageErrors {
+		if errors.Is(err, cstorageErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExistsError reports if the resource exists by the error of the request, the notFoundErr of the resource is not
+// returned, the other errors, such as the bucket not found of an object, are returned
+func parseExistsError(err, notFoundErr error) (bool, error) {
+	if errors.Is(err, notFoundErr) {
+		return false, nil
+	}
+	return helper.IsNil(err), err
+}
+
+// parseStatusCodeError wraps err by the HTTP status code, used when the provider error code is not known
+func parseStatusCodeError(err error, statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return wrapError(ErrPermissionDenied, err)
+	case http.StatusPreconditionFailed:
+		return wrapError(ErrPreconditionFailed, err)
+	case http.StatusBadRequest, http.StatusRequestedRangeNotSatisfiable:
+		return wrapError(ErrInvalidArgument, err)
+	}
+	return err
+}
+
+func parseAwsS3Error(err error) error {
+	if helper.IsNil(err) || isCStorageError(err) {
+		return err
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound":
+			return wrapError(ErrObjectNotFound, err)
+		case "NoSuchBucket":
+			return wrapError(ErrBucketNotFound, err)
+		case "BucketAlreadyExists", "BucketAlreadyOwnedByYou":
+			return wrapError(ErrBucketAlreadyExists, err)
+		case "BucketNotEmpty":
+			return wrapError(ErrBucketNotEmpty, err)
+		case "AccessDenied", "AllAccessDisabled", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return wrapError(ErrPermissionDenied, err)
+		case "PreconditionFailed":
+			return wrapError(ErrPreconditionFailed, err)
+		case "InvalidArgument", "InvalidBucketName", "InvalidRange", "KeyTooLongError", "EntityTooLarge",
+			"EntityTooSmall", "InvalidPart", "InvalidPartOrder":
+			return wrapError(ErrInvalidArgument, err)
+		}
+	}
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		return parseStatusCodeError(err, respErr.HTTPStatusCode())
+	}
+	return err
+}
+
+// isAwsS3HeadNotFound reports if err is the not found of a head request, which has no body, so its error code doesn't
+// tell if the object or the bucket doesn't exist
+func isAwsS3HeadNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotFound"
+}
+
+// isGoogleStorageObjectNotFound reports if err is the object not found, which the SDK also returns when the bucket of
+// the object doesn't exist
+func isGoogleStorageObjectNotFound(err error) bool {
+	return errors.Is(parseGoogleStorageError(err), ErrObjectNotFound)
+}
+
+func parseGoogleStorageError(err error) error {
+	if helper.IsNil(err) || isCStorageError(err) {
+		return err
+	} else if errors.Is(err, storage.ErrObjectNotExist) {
+		return wrapError(ErrObjectNotFound, err)
+	} else if errors.Is(err, storage.ErrBucketNotExist) {
+		return wrapError(ErrBucketNotFound, err)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		// google uses the same status code for different errors, so we check the message
+		message := strings.ToLower(apiErr.Message)
+		switch apiErr.Code {
+		case http.StatusNotFound:
+			// the object message contains its bucket name, so we check it first
+			if !strings.Contains(message, "no such object") && strings.Contains(message, "bucket") {
+				return wrapError(ErrBucketNotFound, err)
+			}
+			return wrapError(ErrObjectNotFound, err)
+		case http.StatusConflict:
+			if strings.Contains(message, "not empty") {
+				return wrapError(ErrBucketNotEmpty, err)
+			}
+			return wrapError(ErrBucketAlreadyExists, err)
+		}
+		return parseStatusCodeError(err, apiErr.Code)
+	}
+	return err
+}
+
+func parseAzureBlobError(err error) error {
+	if helper.IsNil(err) || isCStorageError(err) {
+		return err
+	} else if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return wrapError(ErrObjectNotFound, err)
+	} else if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+		return wrapError(ErrBucketNotFound, err)
+	} else if bloberror.HasCode(err, bloberror.ContainerAlreadyExists, bloberror.ContainerBeingDeleted) {
+		return wrapError(ErrBucketAlreadyExists, err)
+	} else if bloberror.HasCode(err, bloberror.AuthorizationFailure, bloberror.AuthorizationPermissionMismatch,
+		bloberror.InsufficientAccountPermissions) {
+		return wrapError(ErrPermissionDenied, err)
+	} else if bloberror.HasCode(err, bloberror.ConditionNotMet) {
+		return wrapError(ErrPreconditionFailed, err)
+	} else if bloberror.HasCode(err, bloberror.InvalidRange, bloberror.InvalidResourceName,
+		bloberror.OutOfRangeInput) {
+		return wrapError(ErrInvalidArgument, err)
+	}
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return parseStatusCodeError(err, respErr.StatusCode)
+	}
+	return err
+}
@@ -1,48 +1,110 @@
 package cstorage
 
 import (
+	"bytes"
 	"cloud.google.com/go/storage"
 	"context"
-	"fmt"
 	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
 	"io"
+	"net/http"
+	"net/url"
+	"time"
 )
 
 type googleStorageClient struct {
-	client *storage.Client
+	client  *storage.Client
+	options clientOptions
 }
 
-// NewGoogleStorage new instance of connection with Google storage, to close it just use CStorage.Disconnect() or CStorage.SimpleDisconnect()
-func NewGoogleStorage(ctx context.Context, opts ...option.ClientOption) (i CStorage, err error) {
-	client, err := storage.NewClient(ctx, opts...)
+// NewGoogleStorage new instance of connection with Google storage, customized by the opts param (Option), the
+// Google client is configured by WithGoogleClientOptions, to close it just use CStorage.Disconnect() or
+// CStorage.SimpleDisconnect()
+func NewGoogleStorage(ctx context.Context, opts ...Option) (i CStorage, err error) {
+	client, err := storage.NewClient(ctx, newClientOptions(opts).googleOptions...)
 	if helper.IsNil(err) {
-		i = &googleStorageClient{
-			client: client,
-		}
+		i = NewGoogleStorageFromClient(client, opts...)
 	}
 	return i, err
 }
 
+// NewGoogleStorageFromClient new instance of connection with Google storage using the client already configured,
+// customized by the opts param (Option), to close it just use CStorage.Disconnect() or CStorage.SimpleDisconnect()
+func NewGoogleStorageFromClient(client *storage.Client, opts ...Option) CStorage {
+	options := newClientOptions(opts)
+	return newClientStorage(&googleStorageClient{
+		client:  client,
+		options: options,
+	}, options)
+}
+
+func (g googleStorageClient) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(g, name)
+}
+
 func (g googleStorageClient) CreateBucket(ctx context.Context, input CreateBucketInput) error {
-	return g.client.Bucket(input.Bucket).Create(ctx, input.ProjectId, &storage.BucketAttrs{Location: input.Location})
+	err := g.client.Bucket(input.Bucket).Create(ctx, input.ProjectId, &storage.BucketAttrs{Location: input.Location})
+	return parseGoogleStorageError(err)
 }
 
-func (g googleStorageClient) PutObject(ctx context.Context, input PutObjectInput) error {
-	obj := g.client.Bucket(input.Bucket).Object(input.Key)
-	fw := obj.NewWriter(ctx)
-	fw.ContentType = input.MimeType.String()
+func (g googleStorageClient) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	opt := MergeOptsListBucketsByParams(opts)
+	bkts := g.client.Buckets(ctx, opt.ProjectId)
+	bkts.Prefix = opt.Prefix
+	var result []BucketSummary
+	for {
+		attrs, err := bkts.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		} else if helper.IsNotNil(err) {
+			return nil, parseGoogleStorageError(err)
+		}
+		result = append(result, parseGoogleStorageBucketSummary(attrs))
+	}
+	return result, nil
+}
+
+func (g googleStorageClient) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if r, ok := contentReader(input.Content); ok {
+		return g.PutObjectStream(ctx, input, r, opts...)
+	}
 	bytesContent, err := helper.ConvertToBytes(input.Content)
 	if helper.IsNil(err) {
-		_, err = fw.Write(bytesContent)
+		err = g.PutObjectStream(ctx, input, bytes.NewReader(bytesContent), opts...)
 	}
-	_ = fw.Close()
 	return err
 }
 
+func (g googleStorageClient) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	opt := MergeOptsPutObjectByParams(opts)
+	// canceling the context before closing the writer discards the upload
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// each chunk of the resumable upload is retried individually, the whole upload is never sent again
+	obj := g.client.Bucket(input.Bucket).Object(input.Key).Retryer(
+		storage.WithMaxAttempts(*opt.MaxRetries+1),
+		storage.WithPolicy(storage.RetryAlways),
+	)
+	fw := obj.NewWriter(ctx)
+	fw.ContentType = input.MimeType.String()
+	fw.Metadata = input.Metadata
+	fw.CacheControl = input.CacheControl
+	fw.ContentDisposition = input.ContentDisposition
+	fw.ContentEncoding = input.ContentEncoding
+	fw.ContentLanguage = input.ContentLanguage
+	fw.ChunkSize = int(opt.PartSize)
+	_, err := io.Copy(fw, r)
+	if helper.IsNotNil(err) {
+		cancel()
+		_ = fw.Close()
+		return parseGoogleStorageError(err)
+	}
+	return parseGoogleStorageError(fw.Close())
+}
+
 func (g googleStorageClient) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
 	var result []PutObjectOutput
 	for _, input := range inputs {
@@ -56,55 +118,180 @@ func (g googleStorageClient) PutObjects(ctx context.Context, inputs ...PutObject
 	return result
 }
 
+func (g googleStorageClient) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	if err := checkCopyObject(input); helper.IsNotNil(err) {
+		return err
+	}
+	src := g.client.Bucket(input.SrcBucket).Object(input.SrcKey)
+	dst := g.client.Bucket(input.DstBucket).Object(input.DstKey)
+	// the copier rewrites the object in as many requests as needed, so large objects are also copied
+	_, err := dst.CopierFrom(src).Run(ctx)
+	return parseGoogleStorageError(err)
+}
+
+func (g googleStorageClient) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	err := g.CopyObject(ctx, input)
+	if helper.IsNil(err) {
+		err = g.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.SrcBucket,
+			Key:    input.SrcKey,
+		})
+	}
+	return err
+}
+
 func (g googleStorageClient) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
+	return readObject(g.GetObjectReader(ctx, bucket, key))
+}
+
+func (g googleStorageClient) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object,
+	error) {
+	return g.GetObjectRangeReader(ctx, bucket, key, 0, -1)
+}
+
+func (g googleStorageClient) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	if err := checkRange(offset, length); helper.IsNotNil(err) {
+		return nil, nil, err
+	}
 	obj := g.client.Bucket(bucket).Object(key)
 	attrs, err := obj.Attrs(ctx)
 	if helper.IsNotNil(err) {
-		return nil, err
+		return nil, nil, g.parseObjectError(ctx, bucket, err)
+	}
+	// we read the same generation of the attributes, in case the object is overwritten between the calls
+	reader, err := obj.Generation(attrs.Generation).NewRangeReader(ctx, offset, length)
+	if helper.IsNotNil(err) {
+		return nil, nil, g.parseObjectError(ctx, bucket, err)
+	}
+	objResult := parseGoogleStorageObject(attrs)
+	objResult.Url = g.GetObjectUrl(bucket, key)
+	return reader, &objResult, nil
+}
+
+func (g googleStorageClient) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	attrs, err := g.client.Bucket(bucket).Object(key).Attrs(ctx)
+	if helper.IsNotNil(err) {
+		return nil, g.parseObjectError(ctx, bucket, err)
 	}
-	var bs []byte
-	reader, _ := obj.NewReader(ctx)
-	bs, err = io.ReadAll(reader)
 	objResult := parseGoogleStorageObject(attrs)
 	objResult.Url = g.GetObjectUrl(bucket, key)
-	objResult.Content = bs
-	return &objResult, err
+	return &objResult, nil
+}
+
+func (g googleStorageClient) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := g.StatObject(ctx, bucket, key)
+	return parseExistsError(err, ErrObjectNotFound)
+}
+
+func (g googleStorageClient) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	_, err := g.client.Bucket(bucket).Attrs(ctx)
+	return parseExistsError(parseGoogleStorageError(err), ErrBucketNotFound)
 }
 
 func (g googleStorageClient) GetObjectUrl(bucket, key string) string {
-	url := "https://storage.googleapis.com/%s/%s"
-	return fmt.Sprintf(url, bucket, key)
+	if objectUrl, ok := g.options.baseObjectUrl(bucket, key); ok {
+		return objectUrl
+	}
+	return "https://storage.googleapis.com/" + bucket + "/" + encodeObjectKey(key)
+}
+
+func (g googleStorageClient) PresignGetObject(_ context.Context, bucket, key string, opts ...*OptsPresign) (
+	string, error) {
+	opt := MergeOptsPresignByParams(opts)
+	queryParams := url.Values{}
+	if helper.IsNotEmpty(opt.ResponseContentType) {
+		queryParams.Set("response-content-type", opt.ResponseContentType.String())
+	}
+	if helper.IsNotEmpty(opt.ResponseContentDisposition) {
+		queryParams.Set("response-content-disposition", opt.ResponseContentDisposition)
+	}
+	signedUrl, err := g.client.Bucket(bucket).SignedURL(key, &storage.SignedURLOptions{
+		Scheme:          storage.SigningSchemeV4,
+		Method:          http.MethodGet,
+		Expires:         time.Now().Add(opt.Expires),
+		QueryParameters: queryParams,
+	})
+	return signedUrl, parseGoogleStorageError(err)
+}
+
+func (g googleStorageClient) PresignPutObject(_ context.Context, bucket, key string, opts ...*OptsPresign) (
+	string, error) {
+	opt := MergeOptsPresignByParams(opts)
+	signedUrl, err := g.client.Bucket(bucket).SignedURL(key, &storage.SignedURLOptions{
+		Scheme:      storage.SigningSchemeV4,
+		Method:      http.MethodPut,
+		Expires:     time.Now().Add(opt.Expires),
+		ContentType: opt.ContentType.String(),
+	})
+	return signedUrl, parseGoogleStorageError(err)
+}
+
+func (g googleStorageClient) GeneratePostPolicy(_ context.Context, input PostPolicyInput, opts ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	opt := MergeOptsPostPolicyByParams(opts)
+	key, err := parsePostPolicyKey(input, opt)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var conditions []storage.PostPolicyV4Condition
+	if helper.IsEmpty(input.Key) {
+		conditions = append(conditions, storage.ConditionStartsWith("$key", input.KeyPrefix))
+	}
+	if opt.MaxContentLength > 0 {
+		conditions = append(conditions, storage.ConditionContentLengthRange(uint64(opt.MinContentLength),
+			uint64(opt.MaxContentLength)))
+	}
+	policy, err := g.client.Bucket(input.Bucket).GenerateSignedPostPolicyV4(key, &storage.PostPolicyV4Options{
+		Expires:    time.Now().Add(opt.Expires),
+		Fields:     &storage.PolicyV4Fields{ContentType: opt.ContentType.String()},
+		Conditions: conditions,
+	})
+	if helper.IsNotNil(err) {
+		return nil, parseGoogleStorageError(err)
+	}
+	return &PostPolicy{
+		Url:    policy.URL,
+		Fields: policy.Fields,
+	}, nil
 }
 
 func (g googleStorageClient) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary,
 	error) {
+	return listAllObjects(ctx, g, bucket, MergeOptsListObjectsByParams(opts))
+}
+
+func (g googleStorageClient) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (
+	*ObjectPage, error) {
 	opt := MergeOptsListObjectsByParams(opts)
 	bkt := g.client.Bucket(bucket)
 	objs := bkt.Objects(ctx, &storage.Query{
 		Delimiter: opt.Delimiter,
 		Prefix:    opt.Prefix,
 	})
-	var result []ObjectSummary
-	var rErr error
-	for {
-		obj, err := objs.Next()
-		if errors.Is(err, iterator.Done) {
-			break
-		} else if helper.IsNotNil(err) {
-			rErr = err
-			break
-		} else {
-			objResult := parseGoogleStorageObjectSummary(obj)
-			objResult.Url = g.GetObjectUrl(bucket, obj.Name)
-			result = append(result, objResult)
+	var attrs []*storage.ObjectAttrs
+	nextPageToken, err := iterator.NewPager(objs, helper.IfEmptyReturns(opt.MaxResults, 1000), opt.PageToken).
+		NextPage(&attrs)
+	if helper.IsNotNil(err) {
+		return nil, parseGoogleStorageError(err)
+	}
+	page := &ObjectPage{NextPageToken: nextPageToken}
+	for _, obj := range attrs {
+		// the objects grouped by the delimiter only have the prefix filled
+		if helper.IsNotEmpty(obj.Prefix) {
+			page.Prefixes = append(page.Prefixes, obj.Prefix)
+			continue
 		}
+		objResult := parseGoogleStorageObjectSummary(obj)
+		objResult.Url = g.GetObjectUrl(bucket, obj.Name)
+		page.Items = append(page.Items, objResult)
 	}
-	return result, rErr
+	return page, nil
 }
 
 func (g googleStorageClient) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
 	bkt := g.client.Bucket(input.Bucket)
-	return bkt.Object(input.Key).Delete(ctx)
+	return parseGoogleStorageError(bkt.Object(input.Key).Delete(ctx))
 }
 
 func (g googleStorageClient) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
@@ -131,11 +318,12 @@ func (g googleStorageClient) DeleteObjectsByPrefix(ctx context.Context, input De
 		} else if helper.IsNotNil(err) {
 			rErr = err
 			break
-		} else {
-			rErr = bkt.Object(obj.Name).Delete(ctx)
+		} else if err = bkt.Object(obj.Name).Delete(ctx); helper.IsNil(rErr) {
+			// the other objects are still deleted, returning the first error
+			rErr = err
 		}
 	}
-	return rErr
+	return parseGoogleStorageError(rErr)
 }
 
 func (g googleStorageClient) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
@@ -152,7 +340,7 @@ func (g googleStorageClient) DeleteObjectsByPrefixes(ctx context.Context, inputs
 }
 
 func (g googleStorageClient) DeleteBucket(ctx context.Context, bucket string) error {
-	return g.client.Bucket(bucket).Delete(ctx)
+	return parseGoogleStorageError(g.client.Bucket(bucket).Delete(ctx))
 }
 
 func (g googleStorageClient) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
@@ -175,3 +363,14 @@ func (g googleStorageClient) SimpleDisconnect() {
 	_ = g.client.Close()
 	logger.InfoSkipCaller(3, "Connection to google storage closed.")
 }
+
+// parseObjectError parses the error of the object request, the SDK returns the object not found when the bucket
+// doesn't exist, so the bucket is checked to return ErrBucketNotFound, as the other providers
+func (g googleStorageClient) parseObjectError(ctx context.Context, bucket string, err error) error {
+	if isGoogleStorageObjectNotFound(err) {
+		if exists, bucketErr := g.BucketExists(ctx, bucket); helper.IsNil(bucketErr) && !exists {
+			return wrapError(ErrBucketNotFound, err)
+		}
+	}
+	return parseGoogleStorageError(err)
+}
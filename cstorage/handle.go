@@ -0,0 +1,138 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+)
+
+// BucketHandle calls the methods of the CStorage on a single bucket, without the bucket param, so it can be passed to
+// the code that only uses that bucket, it is returned by CStorage.Bucket
+type BucketHandle struct {
+	cs   CStorage
+	name string
+}
+
+// NewBucketHandle returns the handle of the bucket that calls the methods of cs, used by the CStorage
+// implementations on the Bucket method
+func NewBucketHandle(cs CStorage, name string) *BucketHandle {
+	return &BucketHandle{
+		cs:   cs,
+		name: name,
+	}
+}
+
+// Name returns the name of the bucket
+func (b *BucketHandle) Name() string {
+	return b.name
+}
+
+// Exists reports if the bucket exists, when it is not found returns false without error
+func (b *BucketHandle) Exists(ctx context.Context) (bool, error) {
+	return b.cs.BucketExists(ctx, b.name)
+}
+
+// Put set the content of the input in the bucket, the input Bucket field is replaced by the bucket of the handle,
+// see CStorage.PutObject
+func (b *BucketHandle) Put(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	input.Bucket = b.name
+	return b.cs.PutObject(ctx, input, opts...)
+}
+
+// PutStream set the content read from r in the bucket, the input Bucket field is replaced by the bucket of the
+// handle, see CStorage.PutObjectStream
+func (b *BucketHandle) PutStream(ctx context.Context, input PutObjectInput, r io.Reader, opts ...*OptsPutObject) error {
+	input.Bucket = b.name
+	return b.cs.PutObjectStream(ctx, input, r, opts...)
+}
+
+// Copy copies the object srcKey to dstKey inside the bucket, see CStorage.CopyObject
+func (b *BucketHandle) Copy(ctx context.Context, srcKey, dstKey string) error {
+	return b.cs.CopyObject(ctx, b.copyObjectInput(srcKey, dstKey))
+}
+
+// Move moves the object srcKey to dstKey inside the bucket, see CStorage.MoveObject
+func (b *BucketHandle) Move(ctx context.Context, srcKey, dstKey string) error {
+	return b.cs.MoveObject(ctx, b.copyObjectInput(srcKey, dstKey))
+}
+
+// Get returns the object with its content, see CStorage.GetObjectByKey
+func (b *BucketHandle) Get(ctx context.Context, key string) (*Object, error) {
+	return b.cs.GetObjectByKey(ctx, b.name, key)
+}
+
+// Reader returns a reader of the object content, which must be closed by the caller, see CStorage.GetObjectReader
+func (b *BucketHandle) Reader(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	return b.cs.GetObjectReader(ctx, b.name, key)
+}
+
+// RangeReader returns a reader of length bytes of the object content starting at offset, which must be closed by
+// the caller, see CStorage.GetObjectRangeReader
+func (b *BucketHandle) RangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, *Object,
+	error) {
+	return b.cs.GetObjectRangeReader(ctx, b.name, key, offset, length)
+}
+
+// Stat returns the object without its content, see CStorage.StatObject
+func (b *BucketHandle) Stat(ctx context.Context, key string) (*Object, error) {
+	return b.cs.StatObject(ctx, b.name, key)
+}
+
+// ObjectExists reports if the object exists, when it is not found returns false without error, see
+// CStorage.ObjectExists
+func (b *BucketHandle) ObjectExists(ctx context.Context, key string) (bool, error) {
+	return b.cs.ObjectExists(ctx, b.name, key)
+}
+
+// Url returns the object public url, see CStorage.GetObjectUrl
+func (b *BucketHandle) Url(key string) string {
+	return b.cs.GetObjectUrl(b.name, key)
+}
+
+// PresignGet returns a signed url to download the object, see CStorage.PresignGetObject
+func (b *BucketHandle) PresignGet(ctx context.Context, key string, opts ...*OptsPresign) (string, error) {
+	return b.cs.PresignGetObject(ctx, b.name, key, opts...)
+}
+
+// PresignPut returns a signed url to upload the object, see CStorage.PresignPutObject
+func (b *BucketHandle) PresignPut(ctx context.Context, key string, opts ...*OptsPresign) (string, error) {
+	return b.cs.PresignPutObject(ctx, b.name, key, opts...)
+}
+
+// List returns the objects of the bucket, custom query using opts param (OptsListObjects), see CStorage.ListObjects
+func (b *BucketHandle) List(ctx context.Context, opts ...*OptsListObjects) ([]ObjectSummary, error) {
+	return b.cs.ListObjects(ctx, b.name, opts...)
+}
+
+// ListPage returns a single page of objects of the bucket, see CStorage.ListObjectsPage
+func (b *BucketHandle) ListPage(ctx context.Context, opts ...*OptsListObjects) (*ObjectPage, error) {
+	return b.cs.ListObjectsPage(ctx, b.name, opts...)
+}
+
+// Delete deletes the objects of the keys, returning the first error, all the keys are deleted even if one fails
+func (b *BucketHandle) Delete(ctx context.Context, keys ...string) error {
+	inputs := make([]DeleteObjectInput, 0, len(keys))
+	for _, key := range keys {
+		inputs = append(inputs, DeleteObjectInput{Bucket: b.name, Key: key})
+	}
+	for _, output := range b.cs.DeleteObjects(ctx, inputs...) {
+		if helper.IsNotNil(output.Err) {
+			return output.Err
+		}
+	}
+	return nil
+}
+
+// DeletePrefix deletes all objects of the folder (prefix), see CStorage.DeleteObjectsByPrefix
+func (b *BucketHandle) DeletePrefix(ctx context.Context, prefix string) error {
+	return b.cs.DeleteObjectsByPrefix(ctx, DeletePrefixInput{Bucket: b.name, Prefix: prefix})
+}
+
+func (b *BucketHandle) copyObjectInput(srcKey, dstKey string) CopyObjectInput {
+	return CopyObjectInput{
+		SrcBucket: b.name,
+		SrcKey:    srcKey,
+		DstBucket: b.name,
+		DstKey:    dstKey,
+	}
+}
@@ -0,0 +1,89 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"sort"
+	"strings"
+)
+
+// listAllObjects lists the objects of all the pages of ListObjectsPage, starting from the opt PageToken
+func listAllObjects(ctx context.Context, cs CStorage, bucket string, opt *OptsListObjects) ([]ObjectSummary,
+	error) {
+	var result []ObjectSummary
+	pageOpt := *opt
+	for {
+		page, err := cs.ListObjectsPage(ctx, bucket, &pageOpt)
+		if helper.IsNotNil(err) {
+			return result, err
+		}
+		result = append(result, pageSummaries(page)...)
+		if helper.IsEmpty(page.NextPageToken) {
+			return result, nil
+		}
+		pageOpt.PageToken = page.NextPageToken
+	}
+}
+
+// pageSummaries returns the items and the prefixes of the page sorted by key, the prefixes with IsPrefix true
+func pageSummaries(page *ObjectPage) []ObjectSummary {
+	result := make([]ObjectSummary, 0, len(page.Items)+len(page.Prefixes))
+	result = append(result, page.Items...)
+	for _, prefix := range page.Prefixes {
+		result = append(result, ObjectSummary{
+			Key:      prefix,
+			IsPrefix: true,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// listKeysPage builds the page of the sorted keys listed with the opt, the page token is the last key or common
+// prefix of the previous page
+func listKeysPage(keys []string, opt *OptsListObjects, summary func(key string) (ObjectSummary, error)) (
+	*ObjectPage, error) {
+	page := &ObjectPage{}
+	var count int
+	var last string
+	for _, key := range keys {
+		commonPrefix, match := matchListObjects(key, opt)
+		entry := helper.IfEmptyReturns(commonPrefix, key)
+		if !match || (helper.IsNotEmpty(opt.PageToken) && entry <= opt.PageToken) ||
+			(helper.IsNotEmpty(commonPrefix) && commonPrefix == last) {
+			continue
+		} else if opt.MaxResults > 0 && count == opt.MaxResults {
+			page.NextPageToken = last
+			break
+		}
+		if helper.IsNotEmpty(commonPrefix) {
+			page.Prefixes = append(page.Prefixes, commonPrefix)
+		} else {
+			objSummary, err := summary(key)
+			if helper.IsNotNil(err) {
+				return page, err
+			}
+			page.Items = append(page.Items, objSummary)
+		}
+		count++
+		last = entry
+	}
+	return page, nil
+}
+
+// matchListObjects reports if the key is listed with the opt, when the key is grouped by the Delimiter
+// the common prefix is returned
+func matchListObjects(key string, opt *OptsListObjects) (commonPrefix string, match bool) {
+	if !strings.HasPrefix(key, opt.Prefix) {
+		return "", false
+	}
+	if helper.IsNotEmpty(opt.Delimiter) {
+		rest := key[len(opt.Prefix):]
+		if i := strings.Index(rest, opt.Delimiter); i >= 0 {
+			return opt.Prefix + rest[:i+len(opt.Delimiter)], true
+		}
+	}
+	return "", true
+}
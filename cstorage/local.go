@@ -0,0 +1,548 @@
+package cstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// localMetadataDir directory inside the root where the sidecar metadata of the objects is stored
+const localMetadataDir = ".cstorage"
+
+type localStorageClient struct {
+	rootDir string
+	options clientOptions
+}
+
+type localObjectMetadata struct {
+	MimeType           MimeType          `json:"mimeType,omitempty"`
+	LastModifiedAt     time.Time         `json:"lastModifiedAt"`
+	ETag               string            `json:"etag,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	CacheControl       string            `json:"cacheControl,omitempty"`
+	ContentDisposition string            `json:"contentDisposition,omitempty"`
+	ContentEncoding    string            `json:"contentEncoding,omitempty"`
+	ContentLanguage    string            `json:"contentLanguage,omitempty"`
+}
+
+// NewLocalStorage new instance of storage on the local filesystem, each bucket is a subdirectory of rootDir and
+// each object key is a file path inside it, customized by the opts param (Option), to close it just use Disconnect()
+// or SimpleDisconnect()
+func NewLocalStorage(rootDir string, opts ...Option) (CStorage, error) {
+	absRootDir, err := filepath.Abs(rootDir)
+	if helper.IsNil(err) {
+		err = os.MkdirAll(absRootDir, 0755)
+	}
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	options := newClientOptions(opts)
+	return newClientStorage(&localStorageClient{
+		rootDir: absRootDir,
+		options: options,
+	}, options), nil
+}
+
+func (l *localStorageClient) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(l, name)
+}
+
+func (l *localStorageClient) CreateBucket(ctx context.Context, input CreateBucketInput) error {
+	bucketPath, err := l.bucketPath(input.Bucket)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	err = os.Mkdir(bucketPath, 0755)
+	if os.IsExist(err) {
+		return wrapError(ErrBucketAlreadyExists, errors.New("bucket", input.Bucket, "already exists"))
+	}
+	return err
+}
+
+func (l *localStorageClient) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	opt := MergeOptsListBucketsByParams(opts)
+	entries, err := os.ReadDir(l.rootDir)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var result []BucketSummary
+	for _, entry := range entries {
+		// the metadata directory starts with a dot, which is not a valid bucket name
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !strings.HasPrefix(entry.Name(), opt.Prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		// the filesystem doesn't keep the creation time, so we use the modification time of the directory
+		result = append(result, BucketSummary{
+			Name:      entry.Name(),
+			CreatedAt: info.ModTime().UTC(),
+		})
+	}
+	return result, nil
+}
+
+func (l *localStorageClient) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if r, ok := contentReader(input.Content); ok {
+		return l.PutObjectStream(ctx, input, r, opts...)
+	}
+	bytesContent, err := helper.ConvertToBytes(input.Content)
+	if helper.IsNil(err) {
+		err = l.PutObjectStream(ctx, input, bytes.NewReader(bytesContent), opts...)
+	}
+	return err
+}
+
+func (l *localStorageClient) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	objectPath, err := l.objectPath(input.Bucket, input.Key)
+	if helper.IsNil(err) {
+		err = l.checkBucket(input.Bucket)
+	}
+	if helper.IsNil(err) {
+		err = os.MkdirAll(filepath.Dir(objectPath), 0755)
+	}
+	var etag string
+	if helper.IsNil(err) {
+		etag, err = l.writeFile(objectPath, r)
+	}
+	if helper.IsNil(err) {
+		err = l.writeMetadata(input.Bucket, input.Key, localObjectMetadata{
+			MimeType:           input.MimeType,
+			LastModifiedAt:     time.Now().UTC(),
+			ETag:               etag,
+			Metadata:           input.Metadata,
+			CacheControl:       input.CacheControl,
+			ContentDisposition: input.ContentDisposition,
+			ContentEncoding:    input.ContentEncoding,
+			ContentLanguage:    input.ContentLanguage,
+		})
+	}
+	return err
+}
+
+func (l *localStorageClient) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
+	var result []PutObjectOutput
+	for _, input := range inputs {
+		err := l.PutObject(ctx, input)
+		result = append(result, PutObjectOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (l *localStorageClient) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	if err := checkCopyObject(input); helper.IsNotNil(err) {
+		return err
+	}
+	srcPath, obj, err := l.statObject(input.SrcBucket, input.SrcKey)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	file, err := os.Open(srcPath)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer file.Close()
+	return l.PutObjectStream(ctx, parsePutObjectInput(input.DstBucket, input.DstKey, obj), file)
+}
+
+func (l *localStorageClient) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	err := l.CopyObject(ctx, input)
+	if helper.IsNil(err) {
+		err = l.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.SrcBucket,
+			Key:    input.SrcKey,
+		})
+	}
+	return err
+}
+
+func (l *localStorageClient) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
+	return readObject(l.GetObjectReader(ctx, bucket, key))
+}
+
+func (l *localStorageClient) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object,
+	error) {
+	return l.GetObjectRangeReader(ctx, bucket, key, 0, -1)
+}
+
+func (l *localStorageClient) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	if err := checkRange(offset, length); helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	objectPath, obj, err := l.statObject(bucket, key)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	file, err := os.Open(objectPath)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	var start, end int64
+	if helper.IsNil(err) {
+		// the file may be replaced after the stat, so we use the size of the opened one
+		obj.Size = info.Size()
+		start, end, err = rangeBounds(offset, length, info.Size())
+	}
+	if helper.IsNil(err) {
+		_, err = file.Seek(start, io.SeekStart)
+	}
+	if helper.IsNotNil(err) {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	return readCloser{Reader: io.LimitReader(file, end-start), Closer: file}, obj, nil
+}
+
+func (l *localStorageClient) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	_, obj, err := l.statObject(bucket, key)
+	return obj, err
+}
+
+func (l *localStorageClient) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := l.StatObject(ctx, bucket, key)
+	return parseExistsError(err, ErrObjectNotFound)
+}
+
+func (l *localStorageClient) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	return parseExistsError(l.checkBucket(bucket), ErrBucketNotFound)
+}
+
+func (l *localStorageClient) GetObjectUrl(bucket, key string) string {
+	if objectUrl, ok := l.options.baseObjectUrl(bucket, key); ok {
+		return objectUrl
+	}
+	u := url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(filepath.Join(l.rootDir, bucket, filepath.FromSlash(key))),
+	}
+	return u.String()
+}
+
+func (l *localStorageClient) PresignGetObject(_ context.Context, _, _ string, _ ...*OptsPresign) (string, error) {
+	return "", wrapError(ErrNotSupported, errors.New("local storage presigned url"))
+}
+
+func (l *localStorageClient) PresignPutObject(_ context.Context, _, _ string, _ ...*OptsPresign) (string, error) {
+	return "", wrapError(ErrNotSupported, errors.New("local storage presigned url"))
+}
+
+func (l *localStorageClient) GeneratePostPolicy(_ context.Context, _ PostPolicyInput, _ ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	return nil, wrapError(ErrNotSupported, errors.New("local storage post policy"))
+}
+
+func (l *localStorageClient) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary,
+	error) {
+	return listAllObjects(ctx, l, bucket, MergeOptsListObjectsByParams(opts))
+}
+
+func (l *localStorageClient) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (
+	*ObjectPage, error) {
+	opt := MergeOptsListObjectsByParams(opts)
+	keys, err := l.listKeys(bucket, opt.Prefix)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return listKeysPage(keys, opt, func(key string) (ObjectSummary, error) {
+		objectPath, _ := l.objectPath(bucket, key)
+		info, err := os.Stat(objectPath)
+		if helper.IsNotNil(err) {
+			return ObjectSummary{}, err
+		}
+		metadata, err := l.readMetadata(bucket, key, objectPath)
+		if helper.IsNotNil(err) {
+			return ObjectSummary{}, err
+		}
+		return ObjectSummary{
+			Key:            key,
+			Url:            l.GetObjectUrl(bucket, key),
+			Size:           info.Size(),
+			LastModifiedAt: metadata.LastModifiedAt,
+			ETag:           metadata.ETag,
+			Md5:            metadata.ETag,
+		}, nil
+	})
+}
+
+func (l *localStorageClient) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
+	objectPath, err := l.objectPath(input.Bucket, input.Key)
+	if helper.IsNil(err) {
+		err = l.checkBucket(input.Bucket)
+	}
+	if helper.IsNotNil(err) {
+		return err
+	}
+	if !l.fileExists(objectPath) {
+		return wrapError(ErrObjectNotFound,
+			errors.New("object", input.Key, "not found in bucket", input.Bucket))
+	}
+	err = os.Remove(objectPath)
+	if helper.IsNil(err) {
+		l.removeEmptyDirs(filepath.Dir(objectPath), filepath.Join(l.rootDir, input.Bucket))
+		metadataPath := l.metadataPath(input.Bucket, input.Key)
+		_ = os.Remove(metadataPath)
+		l.removeEmptyDirs(filepath.Dir(metadataPath), filepath.Join(l.rootDir, localMetadataDir, input.Bucket))
+	}
+	return err
+}
+
+func (l *localStorageClient) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
+	var result []DeleteObjectsOutput
+	for _, input := range inputs {
+		err := l.DeleteObject(ctx, input)
+		result = append(result, DeleteObjectsOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (l *localStorageClient) DeleteObjectsByPrefix(ctx context.Context, input DeletePrefixInput) error {
+	keys, err := l.listKeys(input.Bucket, input.Prefix)
+	for _, key := range keys {
+		err = l.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.Bucket,
+			Key:    key,
+		})
+	}
+	return err
+}
+
+func (l *localStorageClient) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
+	var result []DeletePrefixOutput
+	for _, input := range inputs {
+		err := l.DeleteObjectsByPrefix(ctx, input)
+		result = append(result, DeletePrefixOutput{
+			Bucket: input.Bucket,
+			Prefix: input.Prefix,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (l *localStorageClient) DeleteBucket(ctx context.Context, bucket string) error {
+	keys, err := l.listKeys(bucket, "")
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsNotEmpty(keys) {
+		return wrapError(ErrBucketNotEmpty, errors.New("bucket", bucket, "is not empty"))
+	}
+	err = os.RemoveAll(filepath.Join(l.rootDir, bucket))
+	if helper.IsNil(err) {
+		err = os.RemoveAll(filepath.Join(l.rootDir, localMetadataDir, bucket))
+	}
+	return err
+}
+
+func (l *localStorageClient) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
+	var result []DeleteBucketsOutput
+	for _, bucket := range buckets {
+		err := l.DeleteBucket(ctx, bucket)
+		result = append(result, DeleteBucketsOutput{
+			Bucket: bucket,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (l *localStorageClient) Disconnect() error {
+	return nil
+}
+
+func (l *localStorageClient) SimpleDisconnect() {
+}
+
+func (l *localStorageClient) bucketPath(bucket string) (string, error) {
+	if helper.IsEmpty(bucket) || strings.HasPrefix(bucket, ".") || strings.ContainsAny(bucket, `/\`) {
+		return "", wrapError(ErrInvalidArgument, errors.New("invalid bucket name:", bucket))
+	}
+	return filepath.Join(l.rootDir, bucket), nil
+}
+
+func (l *localStorageClient) objectPath(bucket, key string) (string, error) {
+	bucketPath, err := l.bucketPath(bucket)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	if helper.IsEmpty(key) || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") ||
+		path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", wrapError(ErrInvalidArgument, errors.New("invalid object key:", key))
+	}
+	return filepath.Join(bucketPath, filepath.FromSlash(key)), nil
+}
+
+func (l *localStorageClient) metadataPath(bucket, key string) string {
+	return filepath.Join(l.rootDir, localMetadataDir, bucket, filepath.FromSlash(key)+".json")
+}
+
+func (l *localStorageClient) checkBucket(bucket string) error {
+	bucketPath, err := l.bucketPath(bucket)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	info, err := os.Stat(bucketPath)
+	if os.IsNotExist(err) || (helper.IsNil(err) && !info.IsDir()) {
+		return wrapError(ErrBucketNotFound, errors.New("bucket", bucket, "not found"))
+	}
+	return err
+}
+
+func (l *localStorageClient) listKeys(bucket, prefix string) ([]string, error) {
+	err := l.checkBucket(bucket)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	bucketPath := filepath.Join(l.rootDir, bucket)
+	var keys []string
+	err = filepath.WalkDir(bucketPath, func(p string, d fs.DirEntry, err error) error {
+		if helper.IsNotNil(err) || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(bucketPath, p)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+func (l *localStorageClient) readMetadata(bucket, key, objectPath string) (localObjectMetadata, error) {
+	var metadata localObjectMetadata
+	bs, err := os.ReadFile(l.metadataPath(bucket, key))
+	if helper.IsNil(err) {
+		err = json.Unmarshal(bs, &metadata)
+	} else if os.IsNotExist(err) {
+		// file created outside the storage, we use the file information instead
+		info, statErr := os.Stat(objectPath)
+		if helper.IsNotNil(statErr) {
+			return metadata, statErr
+		}
+		metadata.MimeType = MimeType(mime.TypeByExtension(filepath.Ext(objectPath)))
+		metadata.LastModifiedAt = info.ModTime().UTC()
+		err = nil
+	}
+	return metadata, err
+}
+
+func (l *localStorageClient) writeMetadata(bucket, key string, metadata localObjectMetadata) error {
+	metadataPath := l.metadataPath(bucket, key)
+	bs, err := json.Marshal(metadata)
+	if helper.IsNil(err) {
+		err = os.MkdirAll(filepath.Dir(metadataPath), 0755)
+	}
+	if helper.IsNil(err) {
+		err = os.WriteFile(metadataPath, bs, 0644)
+	}
+	return err
+}
+
+// statObject returns the path and the data of the object without the Content field
+func (l *localStorageClient) statObject(bucket, key string) (string, *Object, error) {
+	objectPath, err := l.objectPath(bucket, key)
+	if helper.IsNil(err) {
+		err = l.checkBucket(bucket)
+	}
+	if helper.IsNotNil(err) {
+		return "", nil, err
+	}
+	info, err := os.Stat(objectPath)
+	if helper.IsNotNil(err) || info.IsDir() {
+		return "", nil, wrapError(ErrObjectNotFound, errors.New("object", key, "not found in bucket", bucket))
+	}
+	metadata, err := l.readMetadata(bucket, key, objectPath)
+	if helper.IsNotNil(err) {
+		return "", nil, err
+	}
+	return objectPath, &Object{
+		Key:                key,
+		Url:                l.GetObjectUrl(bucket, key),
+		MimeType:           metadata.MimeType,
+		Size:               info.Size(),
+		LastModifiedAt:     metadata.LastModifiedAt,
+		ETag:               metadata.ETag,
+		Metadata:           metadata.Metadata,
+		CacheControl:       metadata.CacheControl,
+		ContentDisposition: metadata.ContentDisposition,
+		ContentEncoding:    metadata.ContentEncoding,
+		ContentLanguage:    metadata.ContentLanguage,
+	}, nil
+}
+
+// writeFile writes the content of r to a temporary file that replaces the file path only when completed, so a
+// failed upload never leaves a partial object, returning the MD5 hash of the content as hexadecimal, used as ETag
+func (l *localStorageClient) writeFile(filePath string, r io.Reader) (string, error) {
+	// bucket names never start with dot, so the temporary directory doesn't conflict with the metadata of a bucket
+	tmpDir := filepath.Join(l.rootDir, localMetadataDir, ".tmp")
+	err := os.MkdirAll(tmpDir, 0755)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	tmpFile, err := os.CreateTemp(tmpDir, "upload-*")
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+	hash := md5.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hash), r)
+	closeErr := tmpFile.Close()
+	if helper.IsNil(err) {
+		err = closeErr
+	}
+	if helper.IsNil(err) {
+		err = os.Chmod(tmpFile.Name(), 0644)
+	}
+	if helper.IsNil(err) {
+		err = os.Rename(tmpFile.Name(), filePath)
+	}
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (l *localStorageClient) removeEmptyDirs(dir, stopDir string) {
+	for strings.HasPrefix(dir, stopDir+string(filepath.Separator)) {
+		if helper.IsNotNil(os.Remove(dir)) {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (l *localStorageClient) fileExists(objectPath string) bool {
+	info, err := os.Stat(objectPath)
+	return helper.IsNil(err) && !info.IsDir()
+}
@@ -2,16 +2,23 @@ package cstorage
 
 import (
 	"context"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"google.golang.org/api/option"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
 const googleStorageProjectId = "GOOGLE_STORAGE_PROJECT_ID"
+const azureStorageConnectionString = "AZURE_STORAGE_CONNECTION_STRING"
+const azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;" +
+	"AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;" +
+	"BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
 const bucketNameDefault = "go-cloud-storage"
 const bucketNameToDeleteDefault = "go-cloud-storage-to-delete"
 const objectKeyDefault = "object-test"
@@ -30,6 +37,13 @@ type testCreateBucket struct {
 	wantErr  bool
 }
 
+type testListBuckets struct {
+	name     string
+	cstorage CStorage
+	opts     *OptsListBuckets
+	wantErr  bool
+}
+
 type testPutObject struct {
 	name     string
 	input    PutObjectInput
@@ -52,6 +66,94 @@ type testListObjects struct {
 	wantErr  bool
 }
 
+type testTransfer struct {
+	name    string
+	src     CStorage
+	dst     CStorage
+	bucket  string
+	key     string
+	wantErr bool
+}
+
+type testOpen struct {
+	name    string
+	url     string
+	wantErr bool
+}
+
+type testClientOptions struct {
+	name     string
+	cstorage CStorage
+	bucket   string
+	wantErr  bool
+}
+
+type testBucketHandle struct {
+	name     string
+	cstorage CStorage
+	bucket   string
+	wantErr  bool
+}
+
+type testClientRetry struct {
+	name       string
+	failures   int
+	maxRetries int
+	wantErr    bool
+}
+
+// flakyStorage fails the first calls of StatObject and PutObject with an unknown error, such as a network error
+type flakyStorage struct {
+	CStorage
+	failures    int
+	putFailures int
+}
+
+func (f *flakyStorage) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if f.putFailures > 0 {
+		f.putFailures--
+		return errors.New("connection reset by peer")
+	}
+	return f.CStorage.PutObject(ctx, input, opts...)
+}
+
+func (f *flakyStorage) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("connection reset by peer")
+	}
+	return f.CStorage.StatObject(ctx, bucket, key)
+}
+
+type testObjectUrl struct {
+	name     string
+	cstorage CStorage
+	bucket   string
+	key      string
+	want     string
+}
+
+type testPresignObject struct {
+	name     string
+	cstorage CStorage
+	opts     *OptsPresign
+	wantErr  bool
+}
+
+type testGeneratePostPolicy struct {
+	name     string
+	cstorage CStorage
+	input    PostPolicyInput
+	wantErr  bool
+}
+
+type testSync struct {
+	name     string
+	cstorage CStorage
+	bucket   string
+	wantErr  bool
+}
+
 type testDeleteObject struct {
 	name     string
 	cstorage CStorage
@@ -86,7 +188,7 @@ func TestMain(m *testing.M) {
 func initGoogleStorage() CStorage {
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
-	cs, err := NewGoogleStorage(ctx, option.WithCredentialsFile("../firebase-admin-sdk.json"))
+	cs, err := NewGoogleStorage(ctx, WithGoogleClientOptions(option.WithCredentialsFile("../firebase-admin-sdk.json")))
 	if helper.IsNotNil(err) {
 		logger.Error("error start google storage:", err)
 		return nil
@@ -114,6 +216,79 @@ func initAwsS3Storage() CStorage {
 	return cs
 }
 
+func initAzureBlobStorage() CStorage {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	connectionString := os.Getenv(azureStorageConnectionString)
+	if helper.IsEmpty(connectionString) {
+		connectionString = azuriteConnectionString
+	}
+	cs, err := NewAzureBlobStorageFromConnectionString(connectionString)
+	if helper.IsNotNil(err) {
+		logger.Error("error start azure blob storage:", err)
+		return nil
+	}
+	_ = cs.CreateBucket(ctx, CreateBucketInput{
+		Bucket: bucketNameDefault,
+	})
+	return cs
+}
+
+func initLocalStorage() CStorage {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	cs, err := NewLocalStorage(filepath.Join(os.TempDir(), bucketNameDefault))
+	if helper.IsNotNil(err) {
+		logger.Error("error start local storage:", err)
+		return nil
+	}
+	_ = cs.CreateBucket(ctx, CreateBucketInput{
+		Bucket: bucketNameDefault,
+	})
+	return cs
+}
+
+func initLocalStorageWithOptions() CStorage {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	cs, err := NewLocalStorage(filepath.Join(os.TempDir(), bucketNameDefault), initTestOptions()...)
+	if helper.IsNotNil(err) {
+		logger.Error("error start local storage:", err)
+		return nil
+	}
+	_ = cs.CreateBucket(ctx, CreateBucketInput{})
+	return cs
+}
+
+func initMemoryStorageWithOptions() CStorage {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	cs := NewMemoryStorage(initTestOptions()...)
+	_ = cs.CreateBucket(ctx, CreateBucketInput{})
+	return cs
+}
+
+func initTestOptions() []Option {
+	return []Option{
+		WithDefaultBucket(bucketNameDefault),
+		WithDefaultMimeType(MimeTypeText),
+		WithTimeout(5 * time.Second),
+		WithRetry(2, 10*time.Millisecond),
+		WithConcurrency(2),
+		WithLogger(nil),
+	}
+}
+
+func initMemoryStorage() CStorage {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	cs := NewMemoryStorage()
+	_ = cs.CreateBucket(ctx, CreateBucketInput{
+		Bucket: bucketNameDefault,
+	})
+	return cs
+}
+
 func initBucket(cs CStorage) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
@@ -159,6 +334,12 @@ func initListTestCreateBucket() []testCreateBucket {
 			cstorage: initAwsS3Storage(),
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			input:    initTestCreateBucketInput(""),
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
 		{
 			name:     "failed google",
 			cstorage: initGoogleStorage(),
@@ -169,6 +350,72 @@ func initListTestCreateBucket() []testCreateBucket {
 			cstorage: initAwsS3Storage(),
 			wantErr:  true,
 		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			input:    initTestCreateBucketInput(""),
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			input:    initTestCreateBucketInput(""),
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestListBuckets() []testListBuckets {
+	return []testListBuckets{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			opts:     initTestOptsListBuckets(),
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			cstorage: initAwsS3Storage(),
+			opts:     initTestOptsListBuckets(),
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			opts:     initTestOptsListBuckets(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed google",
+			cstorage: initGoogleStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			opts:     initTestOptsListBuckets(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
 	}
 }
 
@@ -186,6 +433,12 @@ func initListTestPutObject() []testPutObject {
 			cstorage: initAwsS3Storage(),
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			input:    initTestPutObjectInput(),
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
 		{
 			name:     "failed google",
 			cstorage: initGoogleStorage(),
@@ -196,6 +449,33 @@ func initListTestPutObject() []testPutObject {
 			cstorage: initAwsS3Storage(),
 			wantErr:  true,
 		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			input:    initTestPutObjectInput(),
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			input:    initTestPutObjectInput(),
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
 	}
 }
 
@@ -213,6 +493,12 @@ func initListTestGetObjectByKey() []testGetObjectByKey {
 			cstorage: initAwsS3Storage(),
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			key:      objectKeyDefault,
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
 		{
 			name:     "failed google",
 			cstorage: initGoogleStorage(),
@@ -223,6 +509,33 @@ func initListTestGetObjectByKey() []testGetObjectByKey {
 			cstorage: initAwsS3Storage(),
 			wantErr:  true,
 		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			key:      objectKeyDefault,
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			key:      objectKeyDefault,
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
 	}
 }
 
@@ -247,6 +560,12 @@ func initListTestListObjects() []testListObjects {
 			bucket:   bucketNameDefault,
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
 		{
 			name:     "success empty aws",
 			cstorage: initAwsS3Storage(),
@@ -254,6 +573,13 @@ func initListTestListObjects() []testListObjects {
 			opts:     initTestOptsListObjects(),
 			wantErr:  false,
 		},
+		{
+			name:     "success empty azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   bucketNameDefault,
+			opts:     initTestOptsListObjects(),
+			wantErr:  false,
+		},
 		{
 			name:     "failed google",
 			cstorage: initGoogleStorage(),
@@ -266,72 +592,589 @@ func initListTestListObjects() []testListObjects {
 			bucket:   "bucket-not-exists",
 			wantErr:  true,
 		},
-	}
-}
-
-func initListTestDeleteObject() []testDeleteObject {
-	return []testDeleteObject{
 		{
-			name:     "success google",
-			cstorage: initGoogleStorage(),
-			input:    initTestDeleteObjectInput(),
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   "bucket-not-exists",
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			bucket:   bucketNameDefault,
 			wantErr:  false,
 		},
 		{
-			name:     "success aws",
-			input:    initTestDeleteObjectInput(),
-			cstorage: initAwsS3Storage(),
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			bucket:   bucketNameDefault,
 			wantErr:  false,
 		},
 		{
-			name:     "failed google",
-			cstorage: initGoogleStorage(),
+			name:     "success empty local",
+			cstorage: initLocalStorage(),
+			bucket:   bucketNameDefault,
+			opts:     initTestOptsListObjects(),
+			wantErr:  false,
+		},
+		{
+			name:     "success empty memory",
+			cstorage: initMemoryStorage(),
+			bucket:   bucketNameDefault,
+			opts:     initTestOptsListObjects(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			bucket:   "bucket-not-exists",
 			wantErr:  true,
 		},
 		{
-			name:     "failed aws",
-			cstorage: initAwsS3Storage(),
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			bucket:   "bucket-not-exists",
 			wantErr:  true,
 		},
 	}
 }
 
-func initListTestDeleteObjectsByPrefix() []testDeleteObjectsByPrefix {
-	return []testDeleteObjectsByPrefix{
+func initListTestTransfer() []testTransfer {
+	return []testTransfer{
 		{
-			name:     "success google",
-			cstorage: initGoogleStorage(),
-			input:    initTestDeletePrefixInput(),
-			wantErr:  false,
+			name:    "success google to aws",
+			src:     initGoogleStorage(),
+			dst:     initAwsS3Storage(),
+			bucket:  bucketNameDefault,
+			key:     objectKeyDefault,
+			wantErr: false,
 		},
 		{
-			name:     "success aws",
-			input:    initTestDeletePrefixInput(),
-			cstorage: initAwsS3Storage(),
-			wantErr:  false,
+			name:    "success memory to local",
+			src:     initMemoryStorage(),
+			dst:     initLocalStorage(),
+			bucket:  bucketNameDefault,
+			key:     objectKeyDefault,
+			wantErr: false,
 		},
 		{
-			name:     "failed google",
-			cstorage: initGoogleStorage(),
-			input: DeletePrefixInput{
-				Bucket: "not-exists",
-				Prefix: "",
-			},
-			wantErr: true,
+			name:    "success local to memory",
+			src:     initLocalStorage(),
+			dst:     initMemoryStorage(),
+			bucket:  bucketNameDefault,
+			key:     objectKeyDefault,
+			wantErr: false,
 		},
 		{
-			name:     "failed aws",
-			cstorage: initAwsS3Storage(),
-			input: DeletePrefixInput{
-				Bucket: "not-exists",
-			},
+			name:    "failed memory to local",
+			src:     initMemoryStorage(),
+			dst:     initLocalStorage(),
 			wantErr: true,
 		},
 	}
 }
 
-func initListTestDeleteBucket() []testDeleteBucket {
-	return []testDeleteBucket{
+func initListTestOpen() []testOpen {
+	return []testOpen{
+		{
+			name:    "success google",
+			url:     "gs://" + bucketNameDefault + "?credentials_file=../firebase-admin-sdk.json",
+			wantErr: false,
+		},
+		{
+			name:    "success aws",
+			url:     "s3://" + bucketNameDefault + "?region=us-east-1&endpoint=http://127.0.0.1:9000&path_style=true",
+			wantErr: false,
+		},
+		{
+			name:    "failed aws",
+			url:     "s3://" + bucketNameDefault + "?path_style=invalid",
+			wantErr: true,
+		},
+		{
+			name: "success local",
+			url: "file://" + filepath.ToSlash(filepath.Join(os.TempDir(), bucketNameDefault)) + "?bucket=" +
+				bucketNameDefault,
+			wantErr: false,
+		},
+		{
+			name:    "failed local",
+			url:     "file://",
+			wantErr: true,
+		},
+		{
+			name:    "success memory",
+			url:     "mem://" + bucketNameDefault,
+			wantErr: false,
+		},
+		{
+			name:    "success custom",
+			url:     "custom://" + bucketNameDefault,
+			wantErr: false,
+		},
+		{
+			name:    "failed scheme",
+			url:     "ftp://" + bucketNameDefault,
+			wantErr: true,
+		},
+		{
+			name:    "failed url",
+			url:     "://" + bucketNameDefault,
+			wantErr: true,
+		},
+	}
+}
+
+func initListTestClientOptions() []testClientOptions {
+	return []testClientOptions{
+		{
+			name:     "success local",
+			cstorage: initLocalStorageWithOptions(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorageWithOptions(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory with bucket",
+			cstorage: initMemoryStorageWithOptions(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestClientRetry() []testClientRetry {
+	return []testClientRetry{
+		{
+			name:       "success retry",
+			failures:   2,
+			maxRetries: 2,
+			wantErr:    false,
+		},
+		{
+			name:       "failed retry",
+			failures:   3,
+			maxRetries: 2,
+			wantErr:    true,
+		},
+		{
+			name:     "failed without retry",
+			failures: 1,
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestObjectUrl() []testObjectUrl {
+	return []testObjectUrl{
+		{
+			name:     "aws virtual-hosted style",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "sa-east-1"}),
+			bucket:   bucketNameDefault,
+			key:      "dir/file name+1.txt",
+			want:     "https://go-cloud-storage.s3.sa-east-1.amazonaws.com/dir/file%20name%2B1.txt",
+		},
+		{
+			name:     "aws path style by bucket name",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "sa-east-1"}),
+			bucket:   "go.cloud.storage",
+			key:      "dir/file.txt",
+			want:     "https://s3.sa-east-1.amazonaws.com/go.cloud.storage/dir/file.txt",
+		},
+		{
+			name: "aws path style by endpoint",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "us-east-1",
+				BaseEndpoint: aws.String("http://127.0.0.1:9000")}),
+			bucket: bucketNameDefault,
+			key:    "dir/file.txt",
+			want:   "http://127.0.0.1:9000/go-cloud-storage/dir/file.txt",
+		},
+		{
+			name:     "aws endpoint",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "us-east-1"}, WithEndpoint("https://s3.wasabisys.com")),
+			bucket:   bucketNameDefault,
+			key:      "dir/file.txt",
+			want:     "https://go-cloud-storage.s3.wasabisys.com/dir/file.txt",
+		},
+		{
+			name: "aws endpoint path style",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "auto"},
+				WithEndpoint("https://account.r2.cloudflarestorage.com"), WithForcePathStyle(true),
+				WithUnsignedPayload(true)),
+			bucket: bucketNameDefault,
+			key:    "dir/file.txt",
+			want:   "https://account.r2.cloudflarestorage.com/go-cloud-storage/dir/file.txt",
+		},
+		{
+			name: "aws base url",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "sa-east-1"}, WithBaseUrl("https://cdn.example.com/"),
+				WithDefaultBucket(bucketNameDefault)),
+			bucket: bucketNameDefault,
+			key:    "dir/file name.txt",
+			want:   "https://cdn.example.com/dir/file%20name.txt",
+		},
+		{
+			name: "aws base url other bucket",
+			cstorage: NewAwsS3Storage(aws.Config{Region: "sa-east-1"}, WithBaseUrl("https://cdn.example.com/"),
+				WithDefaultBucket(bucketNameDefault)),
+			bucket: "other-bucket",
+			key:    "dir/file.txt",
+			want:   "https://other-bucket.s3.sa-east-1.amazonaws.com/dir/file.txt",
+		},
+		{
+			name:     "google",
+			cstorage: NewGoogleStorageFromClient(nil),
+			bucket:   bucketNameDefault,
+			key:      "dir/file name?.txt",
+			want:     "https://storage.googleapis.com/go-cloud-storage/dir/file%20name%3F.txt",
+		},
+		{
+			name:     "google base url",
+			cstorage: NewGoogleStorageFromClient(nil, WithBaseUrl("https://cdn.example.com/{bucket}/assets")),
+			bucket:   bucketNameDefault,
+			key:      "dir/file.txt",
+			want:     "https://cdn.example.com/go-cloud-storage/assets/dir/file.txt",
+		},
+		{
+			name:     "google base url other bucket",
+			cstorage: NewGoogleStorageFromClient(nil, WithBaseUrl("https://cdn.example.com/{bucket}/assets")),
+			bucket:   "other-bucket",
+			key:      "dir/file.txt",
+			want:     "https://cdn.example.com/other-bucket/assets/dir/file.txt",
+		},
+		{
+			name:     "memory base url without default bucket",
+			cstorage: NewMemoryStorage(WithBaseUrl("https://cdn.example.com")),
+			bucket:   bucketNameDefault,
+			key:      "dir/file.txt",
+			want:     "mem://go-cloud-storage/dir/file.txt",
+		},
+	}
+}
+
+func initListTestPresignObject() []testPresignObject {
+	return []testPresignObject{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			opts:     initTestOptsPresign(),
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			cstorage: initAwsS3Storage(),
+			opts:     initTestOptsPresign(),
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			opts:     NewOptsPresign().SetExpires(time.Hour),
+			wantErr:  false,
+		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			opts:     initTestOptsPresign(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestGeneratePostPolicy() []testGeneratePostPolicy {
+	return []testGeneratePostPolicy{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			input:    initTestPostPolicyInput(),
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			cstorage: initAwsS3Storage(),
+			input:    initTestPostPolicyInput(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed google",
+			cstorage: initGoogleStorage(),
+			input:    PostPolicyInput{Bucket: bucketNameDefault},
+			wantErr:  true,
+		},
+		{
+			name:     "failed aws",
+			cstorage: initAwsS3Storage(),
+			input:    PostPolicyInput{KeyPrefix: "uploads/"},
+			wantErr:  true,
+		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			input:    initTestPostPolicyInput(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			input:    initTestPostPolicyInput(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			input:    initTestPostPolicyInput(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestSync() []testSync {
+	return []testSync{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			cstorage: initAwsS3Storage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "failed google",
+			cstorage: initGoogleStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestBucketHandle() []testBucketHandle {
+	return []testBucketHandle{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			cstorage: initAwsS3Storage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			bucket:   bucketNameDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success memory with default bucket",
+			cstorage: initMemoryStorageWithOptions(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			bucket:   "not-exists",
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestDeleteObject() []testDeleteObject {
+	return []testDeleteObject{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			input:    initTestDeleteObjectInput(),
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			input:    initTestDeleteObjectInput(),
+			cstorage: initAwsS3Storage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			input:    initTestDeleteObjectInput(),
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed google",
+			cstorage: initGoogleStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed aws",
+			cstorage: initAwsS3Storage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			input:    initTestDeleteObjectInput(),
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			input:    initTestDeleteObjectInput(),
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
+	}
+}
+
+func initListTestDeleteObjectsByPrefix() []testDeleteObjectsByPrefix {
+	return []testDeleteObjectsByPrefix{
+		{
+			name:     "success google",
+			cstorage: initGoogleStorage(),
+			input:    initTestDeletePrefixInput(),
+			wantErr:  false,
+		},
+		{
+			name:     "success aws",
+			input:    initTestDeletePrefixInput(),
+			cstorage: initAwsS3Storage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success azure",
+			input:    initTestDeletePrefixInput(),
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed google",
+			cstorage: initGoogleStorage(),
+			input: DeletePrefixInput{
+				Bucket: "not-exists",
+				Prefix: "",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed aws",
+			cstorage: initAwsS3Storage(),
+			input: DeletePrefixInput{
+				Bucket: "not-exists",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			input: DeletePrefixInput{
+				Bucket: "not-exists",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "success local",
+			input:    initTestDeletePrefixInput(),
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			input:    initTestDeletePrefixInput(),
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			input: DeletePrefixInput{
+				Bucket: "not-exists",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			input: DeletePrefixInput{
+				Bucket: "not-exists",
+			},
+			wantErr: true,
+		},
+	}
+}
+
+func initListTestDeleteBucket() []testDeleteBucket {
+	return []testDeleteBucket{
 		{
 			name:     "success google",
 			cstorage: initGoogleStorage(),
@@ -344,6 +1187,12 @@ func initListTestDeleteBucket() []testDeleteBucket {
 			bucket:   bucketNameToDeleteDefault,
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			bucket:   bucketNameToDeleteDefault,
+			wantErr:  false,
+		},
 		{
 			name:     "failed google",
 			cstorage: initGoogleStorage(),
@@ -354,6 +1203,33 @@ func initListTestDeleteBucket() []testDeleteBucket {
 			cstorage: initAwsS3Storage(),
 			wantErr:  true,
 		},
+		{
+			name:     "failed azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			bucket:   bucketNameToDeleteDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			bucket:   bucketNameToDeleteDefault,
+			wantErr:  false,
+		},
+		{
+			name:     "failed local",
+			cstorage: initLocalStorage(),
+			wantErr:  true,
+		},
+		{
+			name:     "failed memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  true,
+		},
 	}
 }
 
@@ -369,6 +1245,21 @@ func initListTestDisconnect() []testDisconnect {
 			cstorage: initAwsS3Storage(),
 			wantErr:  false,
 		},
+		{
+			name:     "success azure",
+			cstorage: initAzureBlobStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success local",
+			cstorage: initLocalStorage(),
+			wantErr:  false,
+		},
+		{
+			name:     "success memory",
+			cstorage: initMemoryStorage(),
+			wantErr:  false,
+		},
 	}
 }
 
@@ -391,11 +1282,78 @@ func initTestCreateBucketInput(location string) CreateBucketInput {
 
 func initTestPutObjectInput() PutObjectInput {
 	return PutObjectInput{
-		Bucket:   bucketNameDefault,
-		Key:      helper.SimpleConvertToString(time.Now().UnixMilli()),
-		MimeType: MimeTypeJson,
-		Content:  initTestStruct(),
+		Bucket:             bucketNameDefault,
+		Key:                helper.SimpleConvertToString(time.Now().UnixMilli()),
+		MimeType:           MimeTypeJson,
+		Content:            initTestStruct(),
+		Metadata:           map[string]string{"uploader": "go-cloud-storage"},
+		CacheControl:       "no-cache",
+		ContentDisposition: "attachment; filename=\"test.json\"",
+		ContentLanguage:    "en-US",
+	}
+}
+
+func initTestCopyObjectInput(key, suffix string) CopyObjectInput {
+	return CopyObjectInput{
+		SrcBucket: bucketNameDefault,
+		SrcKey:    key,
+		DstBucket: bucketNameDefault,
+		DstKey:    key + "-" + suffix,
+	}
+}
+
+func initTestOptsPutObject() *OptsPutObject {
+	return NewOptsPutObject().SetPartSize(5 << 20).SetConcurrency(2).SetMaxRetries(1)
+}
+
+func initTestOptsListBuckets() *OptsListBuckets {
+	return NewOptsListBuckets().SetProjectId(os.Getenv(googleStorageProjectId)).SetPrefix(bucketNameDefault).
+		SetWithLocation(true)
+}
+
+func initTestOptsMirror() *OptsMirror {
+	return NewOptsMirror().SetConcurrency(2).SetPutObject(initTestOptsPutObject())
+}
+
+func initTestOptsPresign() *OptsPresign {
+	return NewOptsPresign().
+		SetExpires(time.Hour).
+		SetContentType(MimeTypeText).
+		SetResponseContentType(MimeTypeText).
+		SetResponseContentDisposition("attachment; filename=\"test.txt\"")
+}
+
+func initTestPostPolicyInput() PostPolicyInput {
+	return PostPolicyInput{
+		Bucket:    bucketNameDefault,
+		KeyPrefix: "uploads/",
+	}
+}
+
+func initTestOptsPostPolicy() *OptsPostPolicy {
+	return NewOptsPostPolicy().
+		SetExpires(time.Hour).
+		SetContentType(MimeTypePng).
+		SetContentLengthRange(1, 10<<20)
+}
+
+func initTestOptsSync() *OptsSync {
+	return NewOptsSync().SetChecksum(true).SetConcurrency(2).SetPutObject(initTestOptsPutObject())
+}
+
+func initTestSyncDir() string {
+	localDir := filepath.Join(os.TempDir(), bucketNameDefault+"-sync")
+	err := os.MkdirAll(filepath.Join(localDir, "css"), 0755)
+	if helper.IsNil(err) {
+		err = os.WriteFile(filepath.Join(localDir, "index.html"), []byte("<html></html>"), 0644)
+	}
+	if helper.IsNil(err) {
+		err = os.WriteFile(filepath.Join(localDir, "css", "style.css"), []byte("body {}"), 0644)
+	}
+	if helper.IsNotNil(err) {
+		logger.Error("error init sync dir:", err)
 	}
+	return localDir
 }
 
 func initTestOptsListObjects() *OptsListObjects {
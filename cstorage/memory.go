@@ -0,0 +1,419 @@
+package cstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryStorageClient struct {
+	mutex   sync.RWMutex
+	buckets map[string]*memoryBucket
+	options clientOptions
+}
+
+type memoryBucket struct {
+	location  string
+	createdAt time.Time
+	objects   map[string]memoryObject
+}
+
+type memoryObject struct {
+	mimeType           MimeType
+	content            []byte
+	etag               string
+	lastModifiedAt     time.Time
+	metadata           map[string]string
+	cacheControl       string
+	contentDisposition string
+	contentEncoding    string
+	contentLanguage    string
+}
+
+// NewMemoryStorage new thread-safe instance of storage in memory, ideal for unit tests, all the data is lost
+// when the instance is discarded, customized by the opts param (Option), to close it just use Disconnect() or
+// SimpleDisconnect()
+func NewMemoryStorage(opts ...Option) CStorage {
+	options := newClientOptions(opts)
+	return newClientStorage(&memoryStorageClient{
+		buckets: map[string]*memoryBucket{},
+		options: options,
+	}, options)
+}
+
+func (m *memoryStorageClient) Bucket(name string) *BucketHandle {
+	return NewBucketHandle(m, name)
+}
+
+func (m *memoryStorageClient) CreateBucket(ctx context.Context, input CreateBucketInput) error {
+	if helper.IsEmpty(input.Bucket) {
+		return wrapError(ErrInvalidArgument, errors.New("invalid bucket name:", input.Bucket))
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.buckets[input.Bucket]; ok {
+		return wrapError(ErrBucketAlreadyExists, errors.New("bucket", input.Bucket, "already exists"))
+	}
+	m.buckets[input.Bucket] = &memoryBucket{
+		location:  input.Location,
+		createdAt: time.Now().UTC(),
+		objects:   map[string]memoryObject{},
+	}
+	return nil
+}
+
+func (m *memoryStorageClient) ListBuckets(ctx context.Context, opts ...*OptsListBuckets) ([]BucketSummary, error) {
+	opt := MergeOptsListBucketsByParams(opts)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var result []BucketSummary
+	for name, bkt := range m.buckets {
+		if !strings.HasPrefix(name, opt.Prefix) {
+			continue
+		}
+		result = append(result, BucketSummary{
+			Name:      name,
+			Location:  bkt.location,
+			CreatedAt: bkt.createdAt,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (m *memoryStorageClient) PutObject(ctx context.Context, input PutObjectInput, opts ...*OptsPutObject) error {
+	if r, ok := contentReader(input.Content); ok {
+		return m.PutObjectStream(ctx, input, r, opts...)
+	}
+	bytesContent, err := helper.ConvertToBytes(input.Content)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return m.putObject(input, bytesContent)
+}
+
+func (m *memoryStorageClient) PutObjectStream(ctx context.Context, input PutObjectInput, r io.Reader,
+	opts ...*OptsPutObject) error {
+	// the content needs to be in memory anyway, so we read it all before locking the storage
+	bytesContent, err := io.ReadAll(r)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return m.putObject(input, bytesContent)
+}
+
+func (m *memoryStorageClient) putObject(input PutObjectInput, bytesContent []byte) error {
+	if helper.IsEmpty(input.Key) {
+		return wrapError(ErrInvalidArgument, errors.New("invalid object key:", input.Key))
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	bkt, err := m.bucket(input.Bucket)
+	if helper.IsNil(err) {
+		hash := md5.Sum(bytesContent)
+		bkt.objects[input.Key] = memoryObject{
+			mimeType:           input.MimeType,
+			content:            bytesContent,
+			etag:               hex.EncodeToString(hash[:]),
+			lastModifiedAt:     time.Now().UTC(),
+			metadata:           maps.Clone(input.Metadata),
+			cacheControl:       input.CacheControl,
+			contentDisposition: input.ContentDisposition,
+			contentEncoding:    input.ContentEncoding,
+			contentLanguage:    input.ContentLanguage,
+		}
+	}
+	return err
+}
+
+func (m *memoryStorageClient) PutObjects(ctx context.Context, inputs ...PutObjectInput) []PutObjectOutput {
+	var result []PutObjectOutput
+	for _, input := range inputs {
+		err := m.PutObject(ctx, input)
+		result = append(result, PutObjectOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (m *memoryStorageClient) CopyObject(ctx context.Context, input CopyObjectInput) error {
+	if err := checkCopyObject(input); helper.IsNotNil(err) {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	obj, err := m.object(input.SrcBucket, input.SrcKey)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	bkt, err := m.bucket(input.DstBucket)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	// the content is never modified in place, so the copy can share it with the source object
+	obj.lastModifiedAt = time.Now().UTC()
+	bkt.objects[input.DstKey] = obj
+	return nil
+}
+
+func (m *memoryStorageClient) MoveObject(ctx context.Context, input CopyObjectInput) error {
+	err := m.CopyObject(ctx, input)
+	if helper.IsNil(err) {
+		err = m.DeleteObject(ctx, DeleteObjectInput{
+			Bucket: input.SrcBucket,
+			Key:    input.SrcKey,
+		})
+	}
+	return err
+}
+
+func (m *memoryStorageClient) GetObjectByKey(ctx context.Context, bucket, key string) (*Object, error) {
+	return readObject(m.GetObjectReader(ctx, bucket, key))
+}
+
+func (m *memoryStorageClient) GetObjectReader(ctx context.Context, bucket, key string) (io.ReadCloser, *Object,
+	error) {
+	return m.GetObjectRangeReader(ctx, bucket, key, 0, -1)
+}
+
+func (m *memoryStorageClient) GetObjectRangeReader(ctx context.Context, bucket, key string, offset, length int64) (
+	io.ReadCloser, *Object, error) {
+	if err := checkRange(offset, length); helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	obj, err := m.object(bucket, key)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	// the stored content is never changed, the put replaces it, so it is safe to read it without the lock
+	start, end, err := rangeBounds(offset, length, int64(len(obj.content)))
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	return io.NopCloser(bytes.NewReader(obj.content[start:end])), m.parseObject(bucket, key, obj), nil
+}
+
+func (m *memoryStorageClient) StatObject(ctx context.Context, bucket, key string) (*Object, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	obj, err := m.object(bucket, key)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return m.parseObject(bucket, key, obj), nil
+}
+
+func (m *memoryStorageClient) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := m.StatObject(ctx, bucket, key)
+	return parseExistsError(err, ErrObjectNotFound)
+}
+
+func (m *memoryStorageClient) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, err := m.bucket(bucket)
+	return parseExistsError(err, ErrBucketNotFound)
+}
+
+func (m *memoryStorageClient) GetObjectUrl(bucket, key string) string {
+	if objectUrl, ok := m.options.baseObjectUrl(bucket, key); ok {
+		return objectUrl
+	}
+	u := url.URL{
+		Scheme: "mem",
+		Host:   bucket,
+		Path:   "/" + key,
+	}
+	return u.String()
+}
+
+func (m *memoryStorageClient) PresignGetObject(_ context.Context, _, _ string, _ ...*OptsPresign) (string, error) {
+	return "", wrapError(ErrNotSupported, errors.New("memory storage presigned url"))
+}
+
+func (m *memoryStorageClient) PresignPutObject(_ context.Context, _, _ string, _ ...*OptsPresign) (string, error) {
+	return "", wrapError(ErrNotSupported, errors.New("memory storage presigned url"))
+}
+
+func (m *memoryStorageClient) GeneratePostPolicy(_ context.Context, _ PostPolicyInput, _ ...*OptsPostPolicy) (
+	*PostPolicy, error) {
+	return nil, wrapError(ErrNotSupported, errors.New("memory storage post policy"))
+}
+
+func (m *memoryStorageClient) ListObjects(ctx context.Context, bucket string, opts ...*OptsListObjects) ([]ObjectSummary,
+	error) {
+	return listAllObjects(ctx, m, bucket, MergeOptsListObjectsByParams(opts))
+}
+
+func (m *memoryStorageClient) ListObjectsPage(ctx context.Context, bucket string, opts ...*OptsListObjects) (
+	*ObjectPage, error) {
+	opt := MergeOptsListObjectsByParams(opts)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	keys, err := m.listKeys(bucket, opt.Prefix)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return listKeysPage(keys, opt, func(key string) (ObjectSummary, error) {
+		obj := m.buckets[bucket].objects[key]
+		return ObjectSummary{
+			Key:            key,
+			Url:            m.GetObjectUrl(bucket, key),
+			Size:           int64(len(obj.content)),
+			LastModifiedAt: obj.lastModifiedAt,
+			ETag:           obj.etag,
+			Md5:            obj.etag,
+		}, nil
+	})
+}
+
+func (m *memoryStorageClient) DeleteObject(ctx context.Context, input DeleteObjectInput) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, err := m.object(input.Bucket, input.Key)
+	if helper.IsNil(err) {
+		delete(m.buckets[input.Bucket].objects, input.Key)
+	}
+	return err
+}
+
+func (m *memoryStorageClient) DeleteObjects(ctx context.Context, inputs ...DeleteObjectInput) []DeleteObjectsOutput {
+	var result []DeleteObjectsOutput
+	for _, input := range inputs {
+		err := m.DeleteObject(ctx, input)
+		result = append(result, DeleteObjectsOutput{
+			Bucket: input.Bucket,
+			Key:    input.Key,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (m *memoryStorageClient) DeleteObjectsByPrefix(ctx context.Context, input DeletePrefixInput) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	keys, err := m.listKeys(input.Bucket, input.Prefix)
+	for _, key := range keys {
+		delete(m.buckets[input.Bucket].objects, key)
+	}
+	return err
+}
+
+func (m *memoryStorageClient) DeleteObjectsByPrefixes(ctx context.Context, inputs ...DeletePrefixInput) []DeletePrefixOutput {
+	var result []DeletePrefixOutput
+	for _, input := range inputs {
+		err := m.DeleteObjectsByPrefix(ctx, input)
+		result = append(result, DeletePrefixOutput{
+			Bucket: input.Bucket,
+			Prefix: input.Prefix,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (m *memoryStorageClient) DeleteBucket(ctx context.Context, bucket string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	bkt, err := m.bucket(bucket)
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsNotEmpty(bkt.objects) {
+		return wrapError(ErrBucketNotEmpty, errors.New("bucket", bucket, "is not empty"))
+	}
+	delete(m.buckets, bucket)
+	return nil
+}
+
+func (m *memoryStorageClient) DeleteBuckets(ctx context.Context, buckets ...string) []DeleteBucketsOutput {
+	var result []DeleteBucketsOutput
+	for _, bucket := range buckets {
+		err := m.DeleteBucket(ctx, bucket)
+		result = append(result, DeleteBucketsOutput{
+			Bucket: bucket,
+			Err:    err,
+		})
+	}
+	return result
+}
+
+func (m *memoryStorageClient) Disconnect() error {
+	return nil
+}
+
+func (m *memoryStorageClient) SimpleDisconnect() {
+}
+
+// parseObject returns the data of the object without the Content field
+func (m *memoryStorageClient) parseObject(bucket, key string, obj memoryObject) *Object {
+	return &Object{
+		Key:                key,
+		Url:                m.GetObjectUrl(bucket, key),
+		MimeType:           obj.mimeType,
+		Size:               int64(len(obj.content)),
+		LastModifiedAt:     obj.lastModifiedAt,
+		ETag:               obj.etag,
+		Metadata:           maps.Clone(obj.metadata),
+		CacheControl:       obj.cacheControl,
+		ContentDisposition: obj.contentDisposition,
+		ContentEncoding:    obj.contentEncoding,
+		ContentLanguage:    obj.contentLanguage,
+	}
+}
+
+// bucket returns the bucket by name, the caller must hold the mutex
+func (m *memoryStorageClient) bucket(bucket string) (*memoryBucket, error) {
+	bkt, ok := m.buckets[bucket]
+	if !ok {
+		return nil, wrapError(ErrBucketNotFound, errors.New("bucket", bucket, "not found"))
+	}
+	return bkt, nil
+}
+
+// object returns the object by bucket and key, the caller must hold the mutex
+func (m *memoryStorageClient) object(bucket, key string) (memoryObject, error) {
+	bkt, err := m.bucket(bucket)
+	if helper.IsNotNil(err) {
+		return memoryObject{}, err
+	}
+	obj, ok := bkt.objects[key]
+	if !ok {
+		return memoryObject{}, wrapError(ErrObjectNotFound,
+			errors.New("object", key, "not found in bucket", bucket))
+	}
+	return obj, nil
+}
+
+// listKeys returns the sorted keys of the bucket starting with prefix, the caller must hold the mutex
+func (m *memoryStorageClient) listKeys(bucket, prefix string) ([]string, error) {
+	bkt, err := m.bucket(bucket)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var keys []string
+	for key := range bkt.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
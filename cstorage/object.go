@@ -2,13 +2,27 @@ package cstorage
 
 import (
 	"cloud.google.com/go/storage"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// ObjectPage page of objects returned by ListObjectsPage
 type ObjectPage struct {
+	// Items objects of the page
+	Items []ObjectSummary
+	// Prefixes common prefixes of the page, only filled when the listing uses OptsListObjects.Delimiter
+	Prefixes []string
+	// NextPageToken token to list the next page using OptsListObjects.PageToken, if empty there are no more pages
+	NextPageToken string
 }
 
 type Object struct {
@@ -19,6 +33,18 @@ type Object struct {
 	Size           int64
 	VersionId      string
 	LastModifiedAt time.Time
+	// ETag entity tag of the object content generated by the provider, without quotes
+	ETag string
+	// Metadata custom metadata of the object
+	Metadata map[string]string
+	// CacheControl value of the Cache-Control header of the object
+	CacheControl string
+	// ContentDisposition value of the Content-Disposition header of the object
+	ContentDisposition string
+	// ContentEncoding value of the Content-Encoding header of the object
+	ContentEncoding string
+	// ContentLanguage value of the Content-Language header of the object
+	ContentLanguage string
 }
 
 type ObjectSummary struct {
@@ -26,6 +52,13 @@ type ObjectSummary struct {
 	Url            string
 	Size           int64
 	LastModifiedAt time.Time
+	// IsPrefix reports if the Key is a common prefix grouped by the delimiter, like a folder, and not an object
+	IsPrefix bool
+	// ETag entity tag of the object content generated by the provider, without quotes
+	ETag string
+	// Md5 MD5 hash of the content as hexadecimal, empty when the provider doesn't report it, as in the objects
+	// uploaded by parts
+	Md5 string
 }
 
 func (o Object) ParseContent(dest any) error {
@@ -34,10 +67,31 @@ func (o Object) ParseContent(dest any) error {
 
 func parseAwsS3StorageObject(obj *s3.GetObjectOutput) Object {
 	return Object{
-		MimeType:       MimeType(helper.ConvertPointerToValue(obj.ContentType)),
-		Size:           helper.ConvertPointerToValue(obj.ContentLength),
-		VersionId:      helper.ConvertPointerToValue(obj.VersionId),
-		LastModifiedAt: helper.ConvertPointerToValue(obj.LastModified),
+		MimeType:           MimeType(helper.ConvertPointerToValue(obj.ContentType)),
+		Size:               helper.ConvertPointerToValue(obj.ContentLength),
+		VersionId:          helper.ConvertPointerToValue(obj.VersionId),
+		LastModifiedAt:     helper.ConvertPointerToValue(obj.LastModified),
+		ETag:               parseETag(helper.ConvertPointerToValue(obj.ETag)),
+		Metadata:           obj.Metadata,
+		CacheControl:       helper.ConvertPointerToValue(obj.CacheControl),
+		ContentDisposition: helper.ConvertPointerToValue(obj.ContentDisposition),
+		ContentEncoding:    helper.ConvertPointerToValue(obj.ContentEncoding),
+		ContentLanguage:    helper.ConvertPointerToValue(obj.ContentLanguage),
+	}
+}
+
+func parseAwsS3StorageHeadObject(obj *s3.HeadObjectOutput) Object {
+	return Object{
+		MimeType:           MimeType(helper.ConvertPointerToValue(obj.ContentType)),
+		Size:               helper.ConvertPointerToValue(obj.ContentLength),
+		VersionId:          helper.ConvertPointerToValue(obj.VersionId),
+		LastModifiedAt:     helper.ConvertPointerToValue(obj.LastModified),
+		ETag:               parseETag(helper.ConvertPointerToValue(obj.ETag)),
+		Metadata:           obj.Metadata,
+		CacheControl:       helper.ConvertPointerToValue(obj.CacheControl),
+		ContentDisposition: helper.ConvertPointerToValue(obj.ContentDisposition),
+		ContentEncoding:    helper.ConvertPointerToValue(obj.ContentEncoding),
+		ContentLanguage:    helper.ConvertPointerToValue(obj.ContentLanguage),
 	}
 }
 
@@ -46,15 +100,34 @@ func parseAwsS3StorageObjectSummary(obj types.Object) ObjectSummary {
 		Key:            helper.ConvertPointerToValue(obj.Key),
 		Size:           helper.ConvertPointerToValue(obj.Size),
 		LastModifiedAt: helper.ConvertPointerToValue(obj.LastModified),
+		ETag:           parseETag(helper.ConvertPointerToValue(obj.ETag)),
+		Md5:            parseAwsS3Md5(helper.ConvertPointerToValue(obj.ETag)),
+	}
+}
+
+// parseAwsS3Md5 returns the ETag when it is the MD5 of the content, the objects uploaded by parts have the ETag
+// with the number of parts after a dash
+func parseAwsS3Md5(etag string) string {
+	etag = parseETag(etag)
+	if len(etag) != hex.EncodedLen(md5.Size) || strings.Contains(etag, "-") {
+		return ""
 	}
+	return etag
 }
 
 func parseGoogleStorageObject(obj *storage.ObjectAttrs) Object {
 	return Object{
-		Key:            obj.Name,
-		MimeType:       MimeType(obj.ContentType),
-		Size:           obj.Size,
-		LastModifiedAt: obj.Updated,
+		Key:                obj.Name,
+		MimeType:           MimeType(obj.ContentType),
+		Size:               obj.Size,
+		VersionId:          strconv.FormatInt(obj.Generation, 10),
+		LastModifiedAt:     obj.Updated,
+		ETag:               parseETag(obj.Etag),
+		Metadata:           obj.Metadata,
+		CacheControl:       obj.CacheControl,
+		ContentDisposition: obj.ContentDisposition,
+		ContentEncoding:    obj.ContentEncoding,
+		ContentLanguage:    obj.ContentLanguage,
 	}
 }
 
@@ -63,5 +136,137 @@ func parseGoogleStorageObjectSummary(obj *storage.ObjectAttrs) ObjectSummary {
 		Key:            obj.Name,
 		Size:           obj.Size,
 		LastModifiedAt: obj.Updated,
+		ETag:           parseETag(obj.Etag),
+		Md5:            hex.EncodeToString(obj.MD5),
+	}
+}
+
+func parseAzureBlobObject(obj blob.DownloadStreamResponse) Object {
+	return Object{
+		MimeType:           MimeType(helper.ConvertPointerToValue(obj.ContentType)),
+		Size:               helper.ConvertPointerToValue(obj.ContentLength),
+		VersionId:          helper.ConvertPointerToValue(obj.VersionID),
+		LastModifiedAt:     helper.ConvertPointerToValue(obj.LastModified),
+		ETag:               parseETag(string(helper.ConvertPointerToValue(obj.ETag))),
+		Metadata:           parseAzureBlobMetadata(obj.Metadata),
+		CacheControl:       helper.ConvertPointerToValue(obj.CacheControl),
+		ContentDisposition: helper.ConvertPointerToValue(obj.ContentDisposition),
+		ContentEncoding:    helper.ConvertPointerToValue(obj.ContentEncoding),
+		ContentLanguage:    helper.ConvertPointerToValue(obj.ContentLanguage),
+	}
+}
+
+func parseAzureBlobProperties(obj blob.GetPropertiesResponse) Object {
+	return Object{
+		MimeType:           MimeType(helper.ConvertPointerToValue(obj.ContentType)),
+		Size:               helper.ConvertPointerToValue(obj.ContentLength),
+		VersionId:          helper.ConvertPointerToValue(obj.VersionID),
+		LastModifiedAt:     helper.ConvertPointerToValue(obj.LastModified),
+		ETag:               parseETag(string(helper.ConvertPointerToValue(obj.ETag))),
+		Metadata:           parseAzureBlobMetadata(obj.Metadata),
+		CacheControl:       helper.ConvertPointerToValue(obj.CacheControl),
+		ContentDisposition: helper.ConvertPointerToValue(obj.ContentDisposition),
+		ContentEncoding:    helper.ConvertPointerToValue(obj.ContentEncoding),
+		ContentLanguage:    helper.ConvertPointerToValue(obj.ContentLanguage),
+	}
+}
+
+func parseAzureBlobMetadata(metadata map[string]*string) map[string]string {
+	if helper.IsEmpty(metadata) {
+		return nil
+	}
+	result := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		// azure returns the keys canonicalized as HTTP headers, we use lowercase like AWS S3
+		result[strings.ToLower(k)] = helper.ConvertPointerToValue(v)
+	}
+	return result
+}
+
+func parseAzureBlobHTTPHeaders(input PutObjectInput) *blob.HTTPHeaders {
+	return &blob.HTTPHeaders{
+		BlobContentType:        helper.ConvertToPointer(input.MimeType.String()),
+		BlobCacheControl:       parseAzureBlobHeader(input.CacheControl),
+		BlobContentDisposition: parseAzureBlobHeader(input.ContentDisposition),
+		BlobContentEncoding:    parseAzureBlobHeader(input.ContentEncoding),
+		BlobContentLanguage:    parseAzureBlobHeader(input.ContentLanguage),
+	}
+}
+
+// parseAzureBlobHeader returns nil when the header is empty, otherwise azure sends it with an empty value
+func parseAzureBlobHeader(value string) *string {
+	if helper.IsEmpty(value) {
+		return nil
+	}
+	return &value
+}
+
+func parseAzureBlobInputMetadata(metadata map[string]string) map[string]*string {
+	if helper.IsEmpty(metadata) {
+		return nil
+	}
+	result := make(map[string]*string, len(metadata))
+	for k, v := range metadata {
+		result[k] = helper.ConvertToPointer(v)
+	}
+	return result
+}
+
+// checkCopyObject validates the keys of the copy, copying an object to itself is not supported by all providers
+func checkCopyObject(input CopyObjectInput) error {
+	if helper.IsEmpty(input.SrcKey) || helper.IsEmpty(input.DstKey) {
+		return wrapError(ErrInvalidArgument, errors.New("invalid object key, the source and destination keys are",
+			"required"))
+	} else if input.SrcBucket == input.DstBucket && input.SrcKey == input.DstKey {
+		return wrapError(ErrInvalidArgument, errors.New("the source and destination objects are the same"))
+	}
+	return nil
+}
+
+// parsePostPolicyKey returns the key field of the form, validating the input and the content length range
+func parsePostPolicyKey(input PostPolicyInput, opt *OptsPostPolicy) (string, error) {
+	if helper.IsEmpty(input.Bucket) {
+		return "", wrapError(ErrInvalidArgument, errors.New("invalid bucket name:", input.Bucket))
+	} else if opt.MinContentLength < 0 || opt.MinContentLength > opt.MaxContentLength {
+		return "", wrapError(ErrInvalidArgument, errors.New("invalid content length range:", opt.MinContentLength,
+			opt.MaxContentLength))
+	} else if helper.IsNotEmpty(input.Key) {
+		return input.Key, nil
+	} else if helper.IsEmpty(input.KeyPrefix) {
+		return "", wrapError(ErrInvalidArgument, errors.New("invalid object key, the key or the key prefix is",
+			"required"))
+	}
+	return input.KeyPrefix + "${filename}", nil
+}
+
+// parsePutObjectInput returns the input to put the object in the bucket and key keeping its mime type, metadata
+// and headers, the content is not filled
+func parsePutObjectInput(bucket, key string, obj *Object) PutObjectInput {
+	return PutObjectInput{
+		Bucket:             bucket,
+		Key:                key,
+		MimeType:           obj.MimeType,
+		Metadata:           obj.Metadata,
+		CacheControl:       obj.CacheControl,
+		ContentDisposition: obj.ContentDisposition,
+		ContentEncoding:    obj.ContentEncoding,
+		ContentLanguage:    obj.ContentLanguage,
+	}
+}
+
+func parseETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+func parseAzureBlobObjectSummary(obj *container.BlobItem) ObjectSummary {
+	objSummary := ObjectSummary{
+		Key: helper.ConvertPointerToValue(obj.Name),
+	}
+	if helper.IsNotNil(obj.Properties) {
+		objSummary.Size = helper.ConvertPointerToValue(obj.Properties.ContentLength)
+		objSummary.LastModifiedAt = helper.ConvertPointerToValue(obj.Properties.LastModified)
+		objSummary.ETag = parseETag(string(helper.ConvertPointerToValue(obj.Properties.ETag)))
+		objSummary.Md5 = hex.EncodeToString(obj.Properties.ContentMD5)
 	}
+	return objSummary
 }
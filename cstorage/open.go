@@ -0,0 +1,138 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"google.golang.org/api/option"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Opener creates the CStorage of a url scheme, the opts param (Option) already contains the default bucket of the
+// url, when it is informed
+type Opener func(ctx context.Context, u *url.URL, opts ...Option) (CStorage, error)
+
+var openersMutex sync.RWMutex
+
+var openers = map[string]Opener{
+	"s3":     openAwsS3Storage,
+	"gs":     openGoogleStorage,
+	"azblob": openAzureBlobStorage,
+	"file":   openLocalStorage,
+	"mem":    openMemoryStorage,
+}
+
+// Register makes the Opener available to Open by the url scheme, so other providers can be opened by url, if the
+// scheme is already registered its Opener is replaced
+func Register(scheme string, opener Opener) {
+	if helper.IsEmpty(scheme) || opener == nil {
+		panic("cstorage: Register scheme and opener are required")
+	}
+	openersMutex.Lock()
+	defer openersMutex.Unlock()
+	openers[strings.ToLower(scheme)] = opener
+}
+
+// Open creates the CStorage by the url, the scheme is the provider and the host is the default bucket, which is
+// optional, customized by the opts param (Option) and by the query parameters of the url, such as:
+//
+//   - s3://bucket?region=us-east-1&profile=default&endpoint=http://localhost:9000&path_style=true&unsigned_payload=true
+//   - gs://bucket?credentials_file=/etc/gcp/credentials.json
+//   - azblob://container, connected by the AZURE_STORAGE_CONNECTION_STRING environment variable
+//   - file:///var/data?bucket=bucket, the path is the root directory
+//   - mem://bucket, the bucket is created
+//
+// other schemes can be added by Register, when the scheme is not registered returns ErrNotSupported
+func Open(ctx context.Context, rawUrl string, opts ...Option) (CStorage, error) {
+	u, err := url.Parse(rawUrl)
+	if helper.IsNotNil(err) {
+		return nil, wrapError(ErrInvalidArgument, err)
+	}
+	openersMutex.RLock()
+	opener, ok := openers[strings.ToLower(u.Scheme)]
+	openersMutex.RUnlock()
+	if !ok {
+		return nil, wrapError(ErrNotSupported, errors.New("url scheme:", u.Scheme))
+	}
+	bucket := u.Host
+	if strings.EqualFold(u.Scheme, "file") {
+		bucket = u.Query().Get("bucket")
+	}
+	if helper.IsNotEmpty(bucket) {
+		// the opts param is applied after, so it has priority
+		opts = append([]Option{WithDefaultBucket(bucket)}, opts...)
+	}
+	return opener(ctx, u, opts...)
+}
+
+func openAwsS3Storage(ctx context.Context, u *url.URL, opts ...Option) (CStorage, error) {
+	query := u.Query()
+	var loadOpts []func(*config.LoadOptions) error
+	if helper.IsNotEmpty(query.Get("region")) {
+		loadOpts = append(loadOpts, config.WithRegion(query.Get("region")))
+	}
+	if helper.IsNotEmpty(query.Get("profile")) {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(query.Get("profile")))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var urlOpts []Option
+	if helper.IsNotEmpty(query.Get("endpoint")) {
+		urlOpts = append(urlOpts, WithEndpoint(query.Get("endpoint")))
+	}
+	for name, opt := range map[string]func(b bool) Option{
+		"path_style":       WithForcePathStyle,
+		"unsigned_payload": WithUnsignedPayload,
+	} {
+		if !query.Has(name) {
+			continue
+		}
+		b, err := strconv.ParseBool(query.Get(name))
+		if helper.IsNotNil(err) {
+			return nil, wrapError(ErrInvalidArgument, errors.New("invalid url query", name, "err:", err))
+		}
+		urlOpts = append(urlOpts, opt(b))
+	}
+	return NewAwsS3Storage(cfg, append(urlOpts, opts...)...), nil
+}
+
+func openGoogleStorage(ctx context.Context, u *url.URL, opts ...Option) (CStorage, error) {
+	if credentialsFile := u.Query().Get("credentials_file"); helper.IsNotEmpty(credentialsFile) {
+		// the opts param is applied after, so it has priority
+		opts = append([]Option{WithGoogleClientOptions(option.WithCredentialsFile(credentialsFile))}, opts...)
+	}
+	return NewGoogleStorage(ctx, opts...)
+}
+
+func openAzureBlobStorage(_ context.Context, _ *url.URL, opts ...Option) (CStorage, error) {
+	connectionString := os.Getenv("AZURE_STORAGE_CONNECTION_STRING")
+	if helper.IsEmpty(connectionString) {
+		return nil, wrapError(ErrInvalidArgument, errors.New("AZURE_STORAGE_CONNECTION_STRING environment variable is",
+			"required"))
+	}
+	return NewAzureBlobStorageFromConnectionString(connectionString, opts...)
+}
+
+func openLocalStorage(_ context.Context, u *url.URL, opts ...Option) (CStorage, error) {
+	if helper.IsEmpty(u.Path) {
+		return nil, wrapError(ErrInvalidArgument, errors.New("url path is required as the root directory"))
+	}
+	return NewLocalStorage(u.Path, opts...)
+}
+
+func openMemoryStorage(ctx context.Context, u *url.URL, opts ...Option) (CStorage, error) {
+	cs := NewMemoryStorage(opts...)
+	if helper.IsNotEmpty(u.Host) {
+		if err := cs.CreateBucket(ctx, CreateBucketInput{Bucket: u.Host}); helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
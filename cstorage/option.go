@@ -2,6 +2,12 @@ package cstorage
 
 import (
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"google.golang.org/api/option"
+	"time"
 )
 
 // OptsListObjects bucket object search options
@@ -20,6 +26,13 @@ type OptsListObjects struct {
 	// whose names begin with this prefix.
 	// Optional.
 	Prefix string
+	// MaxResults maximum number of items and prefixes returned by each page of ListObjectsPage, the providers
+	// may return fewer results, if empty using the default of the provider.
+	// Optional.
+	MaxResults int
+	// PageToken token returned by the previous page in ObjectPage.NextPageToken, to continue listing from it.
+	// Optional.
+	PageToken string
 }
 
 // NewOptsListObjects creates a new OptsListObjects instance
@@ -39,6 +52,18 @@ func (o *OptsListObjects) SetPrefix(s string) *OptsListObjects {
 	return o
 }
 
+// SetMaxResults sets value for the MaxResults field
+func (o *OptsListObjects) SetMaxResults(i int) *OptsListObjects {
+	o.MaxResults = i
+	return o
+}
+
+// SetPageToken sets value for the PageToken field
+func (o *OptsListObjects) SetPageToken(s string) *OptsListObjects {
+	o.PageToken = s
+	return o
+}
+
 // MergeOptsListObjectsByParams assembles the OptsListObjects object from optional parameters.
 func MergeOptsListObjectsByParams(opts []*OptsListObjects) *OptsListObjects {
 	result := &OptsListObjects{}
@@ -52,6 +77,533 @@ func MergeOptsListObjectsByParams(opts []*OptsListObjects) *OptsListObjects {
 		if helper.IsNotEmpty(opt.Prefix) {
 			result.Prefix = opt.Prefix
 		}
+		if opt.MaxResults > 0 {
+			result.MaxResults = opt.MaxResults
+		}
+		if helper.IsNotEmpty(opt.PageToken) {
+			result.PageToken = opt.PageToken
+		}
+	}
+	return result
+}
+
+// OptsListBuckets bucket search options
+type OptsListBuckets struct {
+	// ProjectId project id where the buckets will be listed (required only google storage)
+	ProjectId string
+	// Prefix is the prefix filter to query buckets
+	// whose names begin with this prefix.
+	// Optional.
+	Prefix string
+	// WithLocation fills the Location of the buckets on AWS S3, which requires a request per bucket, when it fails the
+	// Location is empty. The other providers always fill it.
+	// Optional, default false.
+	WithLocation bool
+}
+
+// NewOptsListBuckets creates a new OptsListBuckets instance
+func NewOptsListBuckets() *OptsListBuckets {
+	return &OptsListBuckets{}
+}
+
+// SetProjectId sets value for the ProjectId field
+func (o *OptsListBuckets) SetProjectId(s string) *OptsListBuckets {
+	o.ProjectId = s
+	return o
+}
+
+// SetPrefix sets value for the Prefix field
+func (o *OptsListBuckets) SetPrefix(s string) *OptsListBuckets {
+	o.Prefix = s
+	return o
+}
+
+// SetWithLocation sets value for the WithLocation field
+func (o *OptsListBuckets) SetWithLocation(b bool) *OptsListBuckets {
+	o.WithLocation = b
+	return o
+}
+
+// MergeOptsListBucketsByParams assembles the OptsListBuckets object from optional parameters.
+func MergeOptsListBucketsByParams(opts []*OptsListBuckets) *OptsListBuckets {
+	result := &OptsListBuckets{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.ProjectId) {
+			result.ProjectId = opt.ProjectId
+		}
+		if helper.IsNotEmpty(opt.Prefix) {
+			result.Prefix = opt.Prefix
+		}
+		if opt.WithLocation {
+			result.WithLocation = true
+		}
+	}
+	return result
+}
+
+// OptsMirror options of the transfer of the objects by Mirror
+type OptsMirror struct {
+	// Concurrency number of objects transferred at the same time.
+	// Optional, if empty using 5.
+	Concurrency int
+	// PutObject upload options of each object on the destination storage.
+	// Optional.
+	PutObject *OptsPutObject
+}
+
+// NewOptsMirror creates a new OptsMirror instance
+func NewOptsMirror() *OptsMirror {
+	return &OptsMirror{}
+}
+
+// SetConcurrency sets value for the Concurrency field
+func (o *OptsMirror) SetConcurrency(i int) *OptsMirror {
+	o.Concurrency = i
+	return o
+}
+
+// SetPutObject sets value for the PutObject field
+func (o *OptsMirror) SetPutObject(opt *OptsPutObject) *OptsMirror {
+	o.PutObject = opt
+	return o
+}
+
+// MergeOptsMirrorByParams assembles the OptsMirror object from optional parameters.
+func MergeOptsMirrorByParams(opts []*OptsMirror) *OptsMirror {
+	result := &OptsMirror{
+		Concurrency: 5,
+	}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if opt.Concurrency > 0 {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsNotNil(opt.PutObject) {
+			result.PutObject = opt.PutObject
+		}
+	}
+	return result
+}
+
+// OptsSync options of SyncUp and SyncDown
+type OptsSync struct {
+	// Delete removes the destination files that don't exist in the source, on SyncUp the objects of the prefix
+	// and on SyncDown the files of the local directory.
+	// Optional, if empty the extraneous files are kept.
+	Delete bool
+	// DryRun only returns the actions that would be taken, without transferring or removing any file.
+	// Optional.
+	DryRun bool
+	// Checksum compares the MD5 hash of the files with the same size, instead of the modification time, when the
+	// provider doesn't report the hash of the object the modification time is used.
+	// Optional.
+	Checksum bool
+	// Concurrency number of files transferred at the same time.
+	// Optional, if empty using 5.
+	Concurrency int
+	// PutObject upload options of each file on SyncUp.
+	// Optional.
+	PutObject *OptsPutObject
+}
+
+// NewOptsSync creates a new OptsSync instance
+func NewOptsSync() *OptsSync {
+	return &OptsSync{}
+}
+
+// SetDelete sets value for the Delete field
+func (o *OptsSync) SetDelete(b bool) *OptsSync {
+	o.Delete = b
+	return o
+}
+
+// SetDryRun sets value for the DryRun field
+func (o *OptsSync) SetDryRun(b bool) *OptsSync {
+	o.DryRun = b
+	return o
+}
+
+// SetChecksum sets value for the Checksum field
+func (o *OptsSync) SetChecksum(b bool) *OptsSync {
+	o.Checksum = b
+	return o
+}
+
+// SetConcurrency sets value for the Concurrency field
+func (o *OptsSync) SetConcurrency(i int) *OptsSync {
+	o.Concurrency = i
+	return o
+}
+
+// SetPutObject sets value for the PutObject field
+func (o *OptsSync) SetPutObject(opt *OptsPutObject) *OptsSync {
+	o.PutObject = opt
+	return o
+}
+
+// MergeOptsSyncByParams assembles the OptsSync object from optional parameters.
+func MergeOptsSyncByParams(opts []*OptsSync) *OptsSync {
+	result := &OptsSync{
+		Concurrency: 5,
+	}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if opt.Delete {
+			result.Delete = opt.Delete
+		}
+		if opt.DryRun {
+			result.DryRun = opt.DryRun
+		}
+		if opt.Checksum {
+			result.Checksum = opt.Checksum
+		}
+		if opt.Concurrency > 0 {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsNotNil(opt.PutObject) {
+			result.PutObject = opt.PutObject
+		}
+	}
+	return result
+}
+
+// OptsPresign options of the presigned urls returned by PresignGetObject and PresignPutObject
+type OptsPresign struct {
+	// Expires duration that the url is valid, AWS S3 and Google storage accept at most 7 days.
+	// Optional, if empty using 15 minutes.
+	Expires time.Duration
+	// ContentType mime type that the request sends in the Content-Type header, used only by PresignPutObject, on
+	// Google storage the request with a different mime type is rejected, not supported by Azure Blob storage.
+	// Optional.
+	ContentType MimeType
+	// ResponseContentType overrides the Content-Type header returned by the download, used only by
+	// PresignGetObject, not supported by Azure Blob storage.
+	// Optional.
+	ResponseContentType MimeType
+	// ResponseContentDisposition overrides the Content-Disposition header returned by the download, such as
+	// attachment; filename="report.pdf", used only by PresignGetObject, not supported by Azure Blob storage.
+	// Optional.
+	ResponseContentDisposition string
+}
+
+// NewOptsPresign creates a new OptsPresign instance
+func NewOptsPresign() *OptsPresign {
+	return &OptsPresign{}
+}
+
+// SetExpires sets value for the Expires field
+func (o *OptsPresign) SetExpires(d time.Duration) *OptsPresign {
+	o.Expires = d
+	return o
+}
+
+// SetContentType sets value for the ContentType field
+func (o *OptsPresign) SetContentType(m MimeType) *OptsPresign {
+	o.ContentType = m
+	return o
+}
+
+// SetResponseContentType sets value for the ResponseContentType field
+func (o *OptsPresign) SetResponseContentType(m MimeType) *OptsPresign {
+	o.ResponseContentType = m
+	return o
+}
+
+// SetResponseContentDisposition sets value for the ResponseContentDisposition field
+func (o *OptsPresign) SetResponseContentDisposition(s string) *OptsPresign {
+	o.ResponseContentDisposition = s
+	return o
+}
+
+// MergeOptsPresignByParams assembles the OptsPresign object from optional parameters.
+func MergeOptsPresignByParams(opts []*OptsPresign) *OptsPresign {
+	result := &OptsPresign{
+		Expires: 15 * time.Minute,
+	}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if opt.Expires > 0 {
+			result.Expires = opt.Expires
+		}
+		if helper.IsNotEmpty(opt.ContentType) {
+			result.ContentType = opt.ContentType
+		}
+		if helper.IsNotEmpty(opt.ResponseContentType) {
+			result.ResponseContentType = opt.ResponseContentType
+		}
+		if helper.IsNotEmpty(opt.ResponseContentDisposition) {
+			result.ResponseContentDisposition = opt.ResponseContentDisposition
+		}
+	}
+	return result
+}
+
+// OptsPostPolicy conditions of the HTML form upload generated by GeneratePostPolicy
+type OptsPostPolicy struct {
+	// Expires duration that the form is valid, AWS S3 and Google storage accept at most 7 days.
+	// Optional, if empty using 15 minutes.
+	Expires time.Duration
+	// ContentType mime type of the uploaded object, the form must send it in the Content-Type field.
+	// Optional, if empty the form can't send the Content-Type field.
+	ContentType MimeType
+	// MinContentLength minimum size in bytes of the uploaded content, used only with the MaxContentLength.
+	// Optional.
+	MinContentLength int64
+	// MaxContentLength maximum size in bytes of the uploaded content.
+	// Optional, if empty the size is not limited.
+	MaxContentLength int64
+}
+
+// NewOptsPostPolicy creates a new OptsPostPolicy instance
+func NewOptsPostPolicy() *OptsPostPolicy {
+	return &OptsPostPolicy{}
+}
+
+// SetExpires sets value for the Expires field
+func (o *OptsPostPolicy) SetExpires(d time.Duration) *OptsPostPolicy {
+	o.Expires = d
+	return o
+}
+
+// SetContentType sets value for the ContentType field
+func (o *OptsPostPolicy) SetContentType(m MimeType) *OptsPostPolicy {
+	o.ContentType = m
+	return o
+}
+
+// SetContentLengthRange sets value for the MinContentLength and MaxContentLength fields
+func (o *OptsPostPolicy) SetContentLengthRange(min, max int64) *OptsPostPolicy {
+	o.MinContentLength = min
+	o.MaxContentLength = max
+	return o
+}
+
+// MergeOptsPostPolicyByParams assembles the OptsPostPolicy object from optional parameters.
+func MergeOptsPostPolicyByParams(opts []*OptsPostPolicy) *OptsPostPolicy {
+	result := &OptsPostPolicy{
+		Expires: 15 * time.Minute,
+	}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if opt.Expires > 0 {
+			result.Expires = opt.Expires
+		}
+		if helper.IsNotEmpty(opt.ContentType) {
+			result.ContentType = opt.ContentType
+		}
+		if opt.MaxContentLength > 0 {
+			result.MinContentLength = opt.MinContentLength
+			result.MaxContentLength = opt.MaxContentLength
+		}
+	}
+	return result
+}
+
+// OptsPutObject object upload options, used by the providers that upload large contents by parts
+type OptsPutObject struct {
+	// PartSize size in bytes of each part of the multipart upload, on AWS S3 the content greater than the PartSize
+	// is uploaded by parts, and on Google storage it is the chunk size of the resumable upload.
+	// The content is buffered in memory by parts, so the memory used is PartSize * Concurrency.
+	// Optional, if empty using 8 MiB.
+	PartSize int64
+	// Concurrency number of parts uploaded at the same time, ignored by Google storage, which uploads the
+	// chunks sequentially.
+	// Optional, if empty using 5.
+	Concurrency int
+	// MaxRetries maximum number of times that a part failed by a transient error, such as a network error, is
+	// uploaded again before aborting the upload, 0 disables these retries. On AWS S3 they are in addition to the retries of the SDK retryer of each request, and on Google
+	// storage they are the attempts of each chunk.
+	// Optional, if nil using 3.
+	MaxRetries *int
+}
+
+// NewOptsPutObject creates a new OptsPutObject instance
+func NewOptsPutObject() *OptsPutObject {
+	return &OptsPutObject{}
+}
+
+// SetPartSize sets value for the PartSize field
+func (o *OptsPutObject) SetPartSize(i int64) *OptsPutObject {
+	o.PartSize = i
+	return o
+}
+
+// SetConcurrency sets value for the Concurrency field
+func (o *OptsPutObject) SetConcurrency(i int) *OptsPutObject {
+	o.Concurrency = i
+	return o
+}
+
+// SetMaxRetries sets value for the MaxRetries field
+func (o *OptsPutObject) SetMaxRetries(i int) *OptsPutObject {
+	o.MaxRetries = &i
+	return o
+}
+
+// MergeOptsPutObjectByParams assembles the OptsPutObject object from optional parameters.
+func MergeOptsPutObjectByParams(opts []*OptsPutObject) *OptsPutObject {
+	result := &OptsPutObject{
+		PartSize:    8 << 20,
+		Concurrency: 5,
+		MaxRetries:  helper.ConvertToPointer(3),
+	}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if opt.PartSize > 0 {
+			result.PartSize = opt.PartSize
+		}
+		if opt.Concurrency > 0 {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsNotNil(opt.MaxRetries) {
+			result.MaxRetries = helper.ConvertToPointer(max(*opt.MaxRetries, 0))
+		}
+	}
+	return result
+}
+
+// Option configures the CStorage created by the constructors, such as NewAwsS3Storage, the options that are not
+// used by the provider are ignored
+type Option func(o *clientOptions)
+
+type clientOptions struct {
+	baseUrl         string
+	defaultBucket   string
+	defaultMimeType MimeType
+	logger          bool
+	loggerOptions   *logger.Options
+	timeout         time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	concurrency     int
+	awsS3Options    []func(o *s3.Options)
+	googleOptions   []option.ClientOption
+}
+
+// WithDefaultBucket sets the bucket used by the calls with the empty bucket, so the code that uses a single bucket
+// doesn't need to inform it
+func WithDefaultBucket(bucket string) Option {
+	return func(o *clientOptions) {
+		o.defaultBucket = bucket
+	}
+}
+
+// WithDefaultMimeType sets the mime type of the objects put with the empty PutObjectInput.MimeType
+func WithDefaultMimeType(mimeType MimeType) Option {
+	return func(o *clientOptions) {
+		o.defaultMimeType = mimeType
+	}
+}
+
+// WithLogger logs the calls that fail with the go-logger options, if nil the global options are used
+func WithLogger(opts *logger.Options) Option {
+	return func(o *clientOptions) {
+		o.logger = true
+		o.loggerOptions = opts
+	}
+}
+
+// WithTimeout sets the timeout of each call, including each retry, on GetObjectReader and GetObjectRangeReader the
+// content must be read before the timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry runs again the calls that fail with an unknown error, such as a network error, up to maxRetries times,
+// waiting the backoff before the first retry and doubling it on each next retry, the cstorage errors, such as
+// ErrObjectNotFound, are not retried. The calls that are not idempotent, such as CreateBucket and MoveObject, are not
+// retried, and the uploads are only retried by the providers with the OptsPutObject MaxRetries
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(o *clientOptions) {
+		o.maxRetries = maxRetries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithConcurrency sets the default number of parts uploaded at the same time by PutObject and PutObjectStream,
+// replaced by the OptsPutObject.Concurrency of the call
+func WithConcurrency(concurrency int) Option {
+	return func(o *clientOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithBaseUrl sets the base url of the object urls returned by GetObjectUrl and filled in Object.Url and
+// ObjectSummary.Url, such as a CDN or a custom domain, the url of the object is the base url followed by the
+// encoded key. The placeholder {bucket} of the base url is replaced by the bucket, such as
+// https://{bucket}.cdn.example.com, without it the base url points to the bucket of WithDefaultBucket, and the other
+// buckets use the url of the provider
+func WithBaseUrl(baseUrl string) Option {
+	return func(o *clientOptions) {
+		o.baseUrl = baseUrl
+	}
+}
+
+// WithEndpoint sets the endpoint of the S3-compatible storage, such as MinIO, Ceph, Cloudflare R2 or Wasabi, also used
+// by the object urls, used only by AWS S3 storage
+func WithEndpoint(endpoint string) Option {
+	return WithAwsS3Options(func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+}
+
+// WithForcePathStyle uses the path style addressing, endpoint/bucket/key, instead of the virtual-hosted style,
+// bucket.endpoint/key, required by most S3-compatible storages, such as MinIO, used only by AWS S3 storage
+func WithForcePathStyle(b bool) Option {
+	return WithAwsS3Options(func(o *s3.Options) {
+		o.UsePathStyle = b
+	})
+}
+
+// WithUnsignedPayload doesn't sign the content of the requests, sending the UNSIGNED-PAYLOAD hash instead, which
+// avoids reading the content twice and is required by some S3-compatible storages, the requests are still sent
+// by HTTPS, used only by AWS S3 storage
+func WithUnsignedPayload(b bool) Option {
+	return WithAwsS3Options(func(o *s3.Options) {
+		if b {
+			o.APIOptions = append(o.APIOptions, v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware)
+		}
+	})
+}
+
+// WithAwsS3Options customizes the options of the AWS S3 client, for the settings that don't have an Option, used only
+// by AWS S3 storage
+func WithAwsS3Options(fns ...func(o *s3.Options)) Option {
+	return func(o *clientOptions) {
+		o.awsS3Options = append(o.awsS3Options, fns...)
+	}
+}
+
+// WithGoogleClientOptions customizes the Google client created by NewGoogleStorage, such as the credentials, used only
+// by Google storage
+func WithGoogleClientOptions(opts ...option.ClientOption) Option {
+	return func(o *clientOptions) {
+		o.googleOptions = append(o.googleOptions, opts...)
+	}
+}
+
+func newClientOptions(opts []Option) clientOptions {
+	var result clientOptions
+	for _, opt := range opts {
+		// helper.IsNotNil doesn't detect the nil functions
+		if opt != nil {
+			opt(&result)
+		}
 	}
 	return result
 }
@@ -0,0 +1,127 @@
+package cstorage
+
+import (
+	"context"
+	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// contentReader returns the content as io.Reader when it can be streamed as-is, without conversion
+func contentReader(content any) (io.Reader, bool) {
+	r, ok := content.(io.Reader)
+	return r, ok && helper.IsNotNil(r)
+}
+
+// readObject reads all the content of the reader returned by GetObjectReader into the object, closing it at the end
+func readObject(reader io.ReadCloser, obj *Object, err error) (*Object, error) {
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	defer reader.Close()
+	obj.Content, err = io.ReadAll(reader)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// checkRange validates the range of GetObjectRangeReader, a negative offset reads the last bytes of the object and
+// requires a negative length
+func checkRange(offset, length int64) error {
+	if length == 0 || (offset < 0 && length > 0) {
+		return wrapError(ErrInvalidArgument, errors.New("invalid range: offset", offset, "length", length))
+	}
+	return nil
+}
+
+// rangeBounds returns the start and the end (exclusive) of the range inside an object with the size passed, an
+// offset at or past the end of a non-empty object returns ErrInvalidArgument, as the cloud providers do
+func rangeBounds(offset, length, size int64) (int64, int64, error) {
+	if offset >= size && size > 0 {
+		return 0, 0, wrapError(ErrInvalidArgument, errors.New("invalid range: offset", offset, "size", size))
+	}
+	start := offset
+	if offset < 0 {
+		start = max(size+offset, 0)
+	}
+	start = min(start, size)
+	end := size
+	if length > 0 {
+		end = min(start+length, size)
+	}
+	return start, end, nil
+}
+
+// rangeHeader returns the value of the HTTP Range header of the range, if it is the whole object returns empty
+func rangeHeader(offset, length int64) string {
+	if offset < 0 {
+		return fmt.Sprintf("bytes=%d", offset)
+	} else if length < 0 {
+		if offset == 0 {
+			return ""
+		}
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// contentRangeSize returns the size of the whole object by the HTTP Content-Range header, such as "bytes 0-9/100",
+// if it is not present or the size is unknown returns defaultSize
+func contentRangeSize(contentRange string, defaultSize int64) int64 {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return defaultSize
+	}
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if helper.IsNotNil(err) {
+		return defaultSize
+	}
+	return size
+}
+
+// retry calls fn until it succeeds or it is called maxRetries more times, waiting an exponential backoff between
+// the calls, the cstorage errors, such as ErrBucketNotFound, are permanent, so they are returned without retrying
+func retry(ctx context.Context, maxRetries int, fn func() error) error {
+	err := fn()
+	for attempt := 0; helper.IsNotNil(err) && !isCStorageError(err) && attempt < maxRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After((100 * time.Millisecond) << attempt):
+		}
+		err = fn()
+	}
+	return err
+}
+
+// readerSize returns the number of bytes remaining in the reader when it can be determined without reading it
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if helper.IsNotNil(err) {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if helper.IsNotNil(err) {
+			return 0, false
+		}
+		if _, err = v.Seek(current, io.SeekStart); helper.IsNotNil(err) {
+			return 0, false
+		}
+		return end - current, true
+	}
+	return 0, false
+}
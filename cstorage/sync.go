@@ -0,0 +1,288 @@
+package cstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncOutput output of each file changed by SyncUp and SyncDown
+type SyncOutput struct {
+	// Key of the object in the bucket
+	Key string
+	// Path of the file in the local directory
+	Path string
+	// Action taken on the file, uploading or downloading it, or removing it from the destination
+	Action SyncAction
+	// Err error of the action, nil when the action succeeded or when using OptsSync.DryRun
+	Err error
+}
+
+type syncFile struct {
+	key        string
+	path       string
+	size       int64
+	modifiedAt time.Time
+}
+
+// SyncUp uploads the files of the local directory, including the subdirectories, to the bucket, the key of each
+// object is the prefix followed by the relative path of the file. Only the new files and the changed ones, with a
+// different size or modified after the object, are uploaded, and the mime type is detected by the file extension.
+// The error is only returned when the files or the objects can't be listed, the error of each file is returned in
+// its SyncOutput.
+func SyncUp(ctx context.Context, cs CStorage, localDir, bucket, prefix string, opts ...*OptsSync) ([]SyncOutput,
+	error) {
+	opt := MergeOptsSyncByParams(opts)
+	prefix = syncPrefix(prefix)
+	files, err := listSyncFiles(localDir, prefix)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	objs, err := listSyncObjects(ctx, cs, bucket, prefix)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	objsByKey := make(map[string]ObjectSummary, len(objs))
+	for _, obj := range objs {
+		objsByKey[obj.Key] = obj
+	}
+	var result []SyncOutput
+	for _, file := range files {
+		if obj, ok := objsByKey[file.key]; ok && !syncChanged(file, obj, opt, true) {
+			continue
+		}
+		result = append(result, SyncOutput{Key: file.key, Path: file.path, Action: SyncActionUpload})
+	}
+	if opt.Delete {
+		filesByKey := make(map[string]bool, len(files))
+		for _, file := range files {
+			filesByKey[file.key] = true
+		}
+		for _, obj := range objs {
+			if !filesByKey[obj.Key] {
+				result = append(result, SyncOutput{Key: obj.Key, Action: SyncActionDelete})
+			}
+		}
+	}
+	return runSync(ctx, result, opt, func(output SyncOutput) error {
+		if output.Action == SyncActionDelete {
+			return cs.DeleteObject(ctx, DeleteObjectInput{Bucket: bucket, Key: output.Key})
+		}
+		return syncUpload(ctx, cs, bucket, output, opt)
+	}), nil
+}
+
+// SyncDown downloads the objects of the bucket with the prefix to the local directory, which is created if it
+// doesn't exist, the path of each file is the key of the object without the prefix. Only the new objects and the
+// changed ones, with a different size or modified after the file, are downloaded, and the modification time of
+// the downloaded files is the one of the object. The error is only returned when the files or the objects can't
+// be listed, the error of each object is returned in its SyncOutput.
+func SyncDown(ctx context.Context, cs CStorage, localDir, bucket, prefix string, opts ...*OptsSync) ([]SyncOutput,
+	error) {
+	opt := MergeOptsSyncByParams(opts)
+	prefix = syncPrefix(prefix)
+	// the local directory is created by the first download
+	var files []syncFile
+	var err error
+	if _, statErr := os.Stat(localDir); !os.IsNotExist(statErr) {
+		files, err = listSyncFiles(localDir, prefix)
+	}
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	objs, err := listSyncObjects(ctx, cs, bucket, prefix)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	filesByKey := make(map[string]syncFile, len(files))
+	for _, file := range files {
+		filesByKey[file.key] = file
+	}
+	var result []SyncOutput
+	for _, obj := range objs {
+		if file, ok := filesByKey[obj.Key]; ok && !syncChanged(file, obj, opt, false) {
+			continue
+		}
+		output := SyncOutput{Key: obj.Key, Action: SyncActionDownload}
+		// the key can't write outside the local directory
+		rel := filepath.FromSlash(strings.TrimPrefix(obj.Key, prefix))
+		if filepath.IsLocal(rel) {
+			output.Path = filepath.Join(localDir, rel)
+		} else {
+			output.Err = wrapError(ErrInvalidArgument, errors.New("object key", obj.Key, "is not a valid file path"))
+		}
+		result = append(result, output)
+	}
+	if opt.Delete {
+		objsByKey := make(map[string]bool, len(objs))
+		for _, obj := range objs {
+			objsByKey[obj.Key] = true
+		}
+		for _, file := range files {
+			if !objsByKey[file.key] {
+				result = append(result, SyncOutput{Key: file.key, Path: file.path, Action: SyncActionDelete})
+			}
+		}
+	}
+	return runSync(ctx, result, opt, func(output SyncOutput) error {
+		if output.Action == SyncActionDelete {
+			return os.Remove(output.Path)
+		}
+		return syncDownload(ctx, cs, bucket, output)
+	}), nil
+}
+
+// runSync runs the action of each output concurrently, unless it is a dry run, the outputs with an error are not run
+func runSync(ctx context.Context, result []SyncOutput, opt *OptsSync, fn func(output SyncOutput) error) []SyncOutput {
+	if opt.DryRun {
+		return result
+	}
+	errs := forEachConcurrently(ctx, len(result), opt.Concurrency, func(i int) error {
+		if helper.IsNotNil(result[i].Err) {
+			return result[i].Err
+		}
+		return fn(result[i])
+	})
+	for i := range result {
+		result[i].Err = errs[i]
+	}
+	return result
+}
+
+// syncChanged reports if the file and the object are different, the modification times are compared in seconds,
+// because some providers don't keep the milliseconds
+func syncChanged(file syncFile, obj ObjectSummary, opt *OptsSync, up bool) bool {
+	if file.size != obj.Size {
+		return true
+	} else if opt.Checksum && helper.IsNotEmpty(obj.Md5) {
+		hash, err := fileMd5(file.path)
+		return helper.IsNotNil(err) || hash != obj.Md5
+	}
+	fileModifiedAt := file.modifiedAt.Truncate(time.Second)
+	objModifiedAt := obj.LastModifiedAt.Truncate(time.Second)
+	if up {
+		return fileModifiedAt.After(objModifiedAt)
+	}
+	return objModifiedAt.After(fileModifiedAt)
+}
+
+func syncPrefix(prefix string) string {
+	if helper.IsNotEmpty(prefix) && !strings.HasSuffix(prefix, "/") {
+		return prefix + "/"
+	}
+	return prefix
+}
+
+// listSyncFiles lists the regular files of the local directory recursively, with the key that they have in the
+// bucket
+func listSyncFiles(localDir, prefix string) ([]syncFile, error) {
+	var result []syncFile
+	err := filepath.WalkDir(localDir, func(filePath string, d fs.DirEntry, err error) error {
+		if helper.IsNotNil(err) || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, filePath)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		result = append(result, syncFile{
+			key:        prefix + filepath.ToSlash(rel),
+			path:       filePath,
+			size:       info.Size(),
+			modifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+	return result, err
+}
+
+// listSyncObjects lists the objects of the prefix, ignoring the keys ending with a slash, used as folders
+func listSyncObjects(ctx context.Context, cs CStorage, bucket, prefix string) ([]ObjectSummary, error) {
+	objs, err := cs.ListObjects(ctx, bucket, NewOptsListObjects().SetPrefix(prefix))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var result []ObjectSummary
+	for _, obj := range objs {
+		if !obj.IsPrefix && !strings.HasSuffix(obj.Key, "/") {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+func syncUpload(ctx context.Context, cs CStorage, bucket string, output SyncOutput, opt *OptsSync) error {
+	file, err := os.Open(output.Path)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer file.Close()
+	mimeType := mime.TypeByExtension(filepath.Ext(output.Path))
+	if helper.IsEmpty(mimeType) {
+		mimeType = "application/octet-stream"
+	}
+	return cs.PutObjectStream(ctx, PutObjectInput{
+		Bucket:   bucket,
+		Key:      output.Key,
+		MimeType: MimeType(mimeType),
+	}, file, opt.PutObject)
+}
+
+// syncDownload writes the object to a temporary file that replaces the file only when completed, so a failed
+// download never leaves a partial file
+func syncDownload(ctx context.Context, cs CStorage, bucket string, output SyncOutput) error {
+	reader, obj, err := cs.GetObjectReader(ctx, bucket, output.Key)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer reader.Close()
+	dir := filepath.Dir(output.Path)
+	err = os.MkdirAll(dir, 0755)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(dir, ".cstorage-*")
+	if helper.IsNotNil(err) {
+		return err
+	}
+	_, err = io.Copy(tmpFile, reader)
+	if closeErr := tmpFile.Close(); helper.IsNil(err) {
+		err = closeErr
+	}
+	if helper.IsNil(err) {
+		err = os.Chtimes(tmpFile.Name(), obj.LastModifiedAt, obj.LastModifiedAt)
+	}
+	if helper.IsNil(err) {
+		err = os.Rename(tmpFile.Name(), output.Path)
+	}
+	if helper.IsNotNil(err) {
+		_ = os.Remove(tmpFile.Name())
+	}
+	return err
+}
+
+func fileMd5(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	defer file.Close()
+	hash := md5.New()
+	if _, err = io.Copy(hash, file); helper.IsNotNil(err) {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
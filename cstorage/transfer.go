@@ -0,0 +1,99 @@
+package cstorage
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"sync"
+)
+
+// MirrorInput input to copy the objects of a bucket to a bucket of another storage
+type MirrorInput struct {
+	// SrcBucket name of the bucket where the objects will be read (required)
+	SrcBucket string
+	// DstBucket name of the bucket where the objects will be written, with the same keys of the source (required)
+	DstBucket string
+	// Prefix of the objects that will be copied, if empty copying the whole bucket
+	Prefix string
+}
+
+// TransferOutput output of the transfer of each object by Mirror
+type TransferOutput struct {
+	// Key of the object transferred
+	Key string
+	// Size of the object transferred in bytes
+	Size int64
+	// Err error of the transfer, nil when the object was transferred successfully
+	Err error
+}
+
+// Transfer copies the object from the src storage to the dst storage, which can be of different providers, the
+// content is streamed from the source to the destination without buffering the whole content, and the mime type,
+// metadata and headers are preserved. To copy inside the same storage prefer CStorage.CopyObject, which doesn't
+// download the content.
+func Transfer(ctx context.Context, src, dst CStorage, input CopyObjectInput, opts ...*OptsPutObject) error {
+	if src == dst {
+		if err := checkCopyObject(input); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	reader, obj, err := src.GetObjectReader(ctx, input.SrcBucket, input.SrcKey)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer reader.Close()
+	return dst.PutObjectStream(ctx, parsePutObjectInput(input.DstBucket, input.DstKey, obj), reader, opts...)
+}
+
+// Mirror copies the objects of the source bucket, or only the ones with the prefix, from the src storage to the
+// dst storage using Transfer, several objects are transferred at the same time as configured in OptsMirror.
+// The error is only returned when the objects can't be listed, the error of each object is returned in its
+// TransferOutput, and the objects not transferred because the context was canceled return the context error.
+func Mirror(ctx context.Context, src, dst CStorage, input MirrorInput, opts ...*OptsMirror) ([]TransferOutput,
+	error) {
+	opt := MergeOptsMirrorByParams(opts)
+	objs, err := src.ListObjects(ctx, input.SrcBucket, NewOptsListObjects().SetPrefix(input.Prefix))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	result := make([]TransferOutput, len(objs))
+	errs := forEachConcurrently(ctx, len(objs), opt.Concurrency, func(i int) error {
+		return Transfer(ctx, src, dst, CopyObjectInput{
+			SrcBucket: input.SrcBucket,
+			SrcKey:    objs[i].Key,
+			DstBucket: input.DstBucket,
+			DstKey:    objs[i].Key,
+		}, opt.PutObject)
+	})
+	for i, obj := range objs {
+		result[i] = TransferOutput{
+			Key:  obj.Key,
+			Size: obj.Size,
+			Err:  errs[i],
+		}
+	}
+	return result, nil
+}
+
+// forEachConcurrently calls fn for each index from 0 to n with at most concurrency calls at the same time,
+// returning the error of each call, the indexes not called because the context was canceled return its error
+func forEachConcurrently(ctx context.Context, n, concurrency int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,105 @@
+package cstorage
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// encodeObjectKey percent-encodes each segment of the key keeping the slashes, so it can be used in the url path
+func encodeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		// the plus sign is decoded as a space by some providers
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+// baseUrlBucket placeholder of the base url replaced by the bucket of the object
+const baseUrlBucket = "{bucket}"
+
+// joinObjectUrl returns the url of the object by the base url, which must point to the bucket, such as a CDN
+// or a custom domain
+func joinObjectUrl(baseUrl, key string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/" + encodeObjectKey(key)
+}
+
+// baseObjectUrl returns the url of the object by the base url option, the placeholder {bucket} is replaced by the
+// bucket, without it the base url points to the default bucket, so the other buckets return false and use the url of
+// the provider
+func (o clientOptions) baseObjectUrl(bucket, key string) (string, bool) {
+	if helper.IsEmpty(o.baseUrl) {
+		return "", false
+	} else if strings.Contains(o.baseUrl, baseUrlBucket) {
+		return joinObjectUrl(strings.ReplaceAll(o.baseUrl, baseUrlBucket, url.PathEscape(bucket)), key), true
+	} else if bucket != o.defaultBucket {
+		return "", false
+	}
+	return joinObjectUrl(o.baseUrl, key), true
+}
+
+// awsS3ObjectUrl returns the url of the object by the endpoint, region and addressing style of the client options
+func awsS3ObjectUrl(opts s3.Options, bucket, key string) string {
+	return awsS3BucketUrl(opts, bucket) + "/" + encodeObjectKey(key)
+}
+
+// awsS3BucketUrl returns the url of the bucket, without the trailing slash, the virtual-hosted style is used unless
+// the path style is forced, the bucket is not a valid host name or the endpoint is an IP address
+func awsS3BucketUrl(opts s3.Options, bucket string) string {
+	scheme, host, path := "https", awsS3Host(opts), ""
+	if helper.IsNotNil(opts.BaseEndpoint) {
+		endpoint, err := url.Parse(aws.ToString(opts.BaseEndpoint))
+		if helper.IsNil(err) && helper.IsNotEmpty(endpoint.Host) {
+			scheme, host, path = endpoint.Scheme, endpoint.Host, strings.TrimSuffix(endpoint.Path, "/")
+		}
+	}
+	hostname, _, err := net.SplitHostPort(host)
+	if helper.IsNotNil(err) {
+		hostname = host
+	}
+	if opts.UsePathStyle || helper.IsNotNil(net.ParseIP(hostname)) || !awsS3VirtualHostBucket(bucket, scheme) {
+		path += "/" + bucket
+	} else {
+		host = bucket + "." + host
+	}
+	return scheme + "://" + host + path
+}
+
+// awsS3Host returns the host of the AWS S3 endpoint of the region
+func awsS3Host(opts s3.Options) string {
+	region := opts.Region
+	if helper.IsEmpty(region) {
+		region = "us-east-1"
+	}
+	host := "s3." + region
+	if opts.EndpointOptions.UseDualStackEndpoint == aws.DualStackEndpointStateEnabled {
+		host = "s3.dualstack." + region
+	}
+	if strings.HasPrefix(region, "cn-") {
+		return host + ".amazonaws.com.cn"
+	}
+	return host + ".amazonaws.com"
+}
+
+// awsS3VirtualHostBucket reports if the bucket can be used as a subdomain of the endpoint, with https the bucket
+// can't have dots because they don't match the certificate of the endpoint
+func awsS3VirtualHostBucket(bucket, scheme string) bool {
+	if len(bucket) < 3 || len(bucket) > 63 || (scheme == "https" && strings.Contains(bucket, ".")) {
+		return false
+	}
+	for _, label := range strings.Split(bucket, ".") {
+		if helper.IsEmpty(label) || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}